@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/alcionai/clues/internal/stringify"
@@ -25,7 +26,9 @@ const (
 
 var (
 	initial = makeDefaultHash()
-	config  = DefaultHash()
+	// config holds the configuration set by SetHasher.  A nil pointer
+	// means the DefaultHash is used.
+	config atomic.Pointer[HashCfg]
 )
 
 type HashCfg struct {
@@ -36,7 +39,42 @@ type HashCfg struct {
 // SetHasher sets the hashing configuration used in
 // all concealer structs, and Conceal() and Hash() calls.
 func SetHasher(sc HashCfg) {
-	config = sc
+	config.Store(&sc)
+}
+
+// hashCfg returns the current hashing configuration.
+func hashCfg() HashCfg {
+	if sc := config.Load(); sc != nil {
+		return *sc
+	}
+
+	return DefaultHash()
+}
+
+type displayMode int
+
+const (
+	// DisplayHash displays concealed values as their hash.
+	DisplayHash displayMode = iota
+	// DisplayPlaceholder displays concealed values as a fixed
+	// placeholder: "***".
+	DisplayPlaceholder
+)
+
+// placeholder is the display value of concealed values under the
+// DisplayPlaceholder mode.
+const placeholder = "***"
+
+// display holds the displayMode.  The zero value is DisplayHash.
+var display atomic.Int64
+
+// SetDisplayMode sets how concealed values get displayed by the Conceal(),
+// String(), and fmt calls of secret structs.  The default, DisplayHash,
+// shows the hash of the value.  DisplayPlaceholder shows "***" instead.
+// Regardless of mode, the hash of the value remains available for
+// correlation through the secret's Hash() call.
+func SetDisplayMode(mode displayMode) {
+	display.Store(int64(mode))
 }
 
 // NoHash provides a secrets configuration with
@@ -88,13 +126,23 @@ type secret struct {
 	hashText  string
 }
 
-// use the hashed string in any fmt verb.
-func (s secret) Format(fs fmt.State, verb rune) { io.WriteString(fs, s.hashText) }
-func (s secret) String() string                 { return s.hashText }
-func (s secret) Conceal() string                { return s.hashText }
+// use the displayed string in any fmt verb.
+func (s secret) Format(fs fmt.State, verb rune) { io.WriteString(fs, s.displayText()) }
+func (s secret) String() string                 { return s.displayText() }
+func (s secret) Conceal() string                { return s.displayText() }
+func (s secret) Hash() string                   { return s.hashText }
 func (s secret) PlainString() string            { return s.plainText }
 func (s secret) V() any                         { return s.value }
 
+// displayText produces the concealed value according to the display mode.
+func (s secret) displayText() string {
+	if displayMode(display.Load()) == DisplayPlaceholder && len(s.hashText) > 0 {
+		return placeholder
+	}
+
+	return s.hashText
+}
+
 // ---------------------------------------------------------------------------
 // concealer constructors
 // ---------------------------------------------------------------------------
@@ -135,7 +183,7 @@ func HideAll(a ...any) []secret {
 // Conceal() call always returns a flat string: "***"
 func Mask(a any) secret {
 	return secret{
-		hashText:  "***",
+		hashText:  placeholder,
 		plainText: stringify.Fmt(a)[0],
 		value:     a,
 	}
@@ -146,7 +194,7 @@ func Mask(a any) secret {
 func Conceal(a any) string {
 	// marshal with false or else we hit a double hash (at best)
 	// or an infinite loop (at worst).
-	return ConcealWith(config.HashAlg, stringify.Fmt(a)[0])
+	return ConcealWith(hashCfg().HashAlg, stringify.Fmt(a)[0])
 }
 
 // Conceal runs one of clues' hashing algorithms on
@@ -176,7 +224,7 @@ func ConcealWith(alg hashAlg, s string) string {
 // ---------------------------------------------------------------------------
 
 func hashHmacSha256(s string) string {
-	sig := hmac.New(sha256.New, config.HMACKey)
+	sig := hmac.New(sha256.New, hashCfg().HMACKey)
 	sig.Write([]byte(s))
 
 	return hex.EncodeToString(sig.Sum(nil))[:hashTruncateLen]
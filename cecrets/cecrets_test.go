@@ -302,3 +302,56 @@ func TestHideAll(t *testing.T) {
 		})
 	}
 }
+
+func TestSetDisplayMode(t *testing.T) {
+	var (
+		input = "brunhaldi"
+		hash  = "cddff495fc4a46ef"
+	)
+
+	table := []struct {
+		name   string
+		mode   displayMode
+		expect string
+	}{
+		{
+			name:   "hash",
+			mode:   DisplayHash,
+			expect: hash,
+		},
+		{
+			name:   "placeholder",
+			mode:   DisplayPlaceholder,
+			expect: "***",
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			SetDisplayMode(test.mode)
+			defer SetDisplayMode(DisplayHash)
+
+			h := Hide(input)
+			if h.Conceal() != test.expect {
+				t.Errorf(`expected Conceal() result %q, got %q`, test.expect, h.Conceal())
+			}
+			if h.String() != test.expect {
+				t.Errorf(`expected String() result %q, got %q`, test.expect, h.String())
+			}
+			result := fmt.Sprintf("%v", h)
+			if result != test.expect {
+				t.Errorf(`expected %%v fmt result %q, got %q`, test.expect, result)
+			}
+			if h.Hash() != hash {
+				t.Errorf(`expected Hash() result %q, got %q`, hash, h.Hash())
+			}
+			if h.PlainString() != input {
+				t.Errorf(`expected PlainString() result %q, got %q`, input, h.PlainString())
+			}
+
+			empty := Hide("")
+			if empty.Conceal() != "" {
+				t.Errorf(`expected empty Conceal() result, got %q`, empty.Conceal())
+			}
+		})
+	}
+}
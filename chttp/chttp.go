@@ -2,13 +2,24 @@ package chttp
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/alcionai/clues"
 	"github.com/alcionai/clues/clog"
+	"github.com/alcionai/clues/cluerr"
 	"github.com/alcionai/clues/ctats"
 )
 
+// RequestIDHeader is the request header from which Middleware reads the
+// request ID.  If the header is missing, a new ID gets generated.
+const RequestIDHeader = "X-Request-Id"
+
 // NewInheritorHTTPMiddleware builds a http middleware which automatically
 // inherits initialized clients from the clues ecosystem and embeds them in
 // the request context.  Since clues prefers context-bound client propagation
@@ -34,3 +45,67 @@ func InheritorMiddleware(
 		return http.HandlerFunc(fn)
 	}
 }
+
+// Middleware is a http middleware which starts a span for each request.
+// The span is named after the request's route pattern (see
+// http.Request.Pattern), or its method if no pattern was matched, and
+// the request path is recorded in the span's url.path attribute.
+// Any trace headers in the request are received into the ctx (see
+// clues.ReceiveTrace), so that the span continues the caller's trace.
+// The request ID, read from the X-Request-Id header or generated if
+// missing, is added to the request clues under the "request_id" key.
+//
+// If the handler panics, the span is ended with an error status before
+// the panic continues up the stack.
+//
+// Spans are only produced if otel was initialized in the request ctx,
+// so Middleware should run after the InheritorMiddleware.
+func Middleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		rid := r.Header.Get(RequestIDHeader)
+		if len(rid) == 0 {
+			rid = uuid.NewString()
+		}
+
+		ctx := clues.ReceiveTrace(r.Context(), r.Header)
+		ctx = clues.AddSpan(
+			ctx,
+			spanName(r),
+			cluerr.RequestIDKey, rid)
+
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(semconv.URLPath(r.URL.Path))
+
+		req := r.WithContext(ctx)
+
+		defer func() {
+			// a ServeMux inside next sets the pattern it matched.
+			if len(req.Pattern) > 0 {
+				span.SetName(req.Pattern)
+			}
+
+			if rec := recover(); rec != nil {
+				span.SetStatus(codes.Error, fmt.Sprint(rec))
+				clues.CloseSpan(ctx)
+				panic(rec)
+			}
+
+			clues.CloseSpan(ctx)
+		}()
+
+		next.ServeHTTP(w, req)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// spanName names the request span after the matched route pattern, or
+// the request method if no pattern matched.  The path isn't used, since
+// it would give each resource its own span name.
+func spanName(r *http.Request) string {
+	if len(r.Pattern) > 0 {
+		return r.Pattern
+	}
+
+	return r.Method
+}
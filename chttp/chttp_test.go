@@ -0,0 +1,131 @@
+package chttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/alcionai/clues"
+	"github.com/alcionai/clues/cluerr"
+	"github.com/alcionai/clues/internal/node"
+)
+
+func newTestRequest(
+	t *testing.T,
+	recorder *tracetest.SpanRecorder,
+) *http.Request {
+	provider := sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder))
+	root := &node.Node{OTEL: &node.OTELClient{Tracer: provider.Tracer(t.Name())}}
+
+	req := httptest.NewRequest(http.MethodGet, "/path", nil)
+
+	return req.WithContext(node.EmbedInCtx(context.Background(), root))
+}
+
+func TestMiddleware(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	defer otel.SetTextMapPropagator(prev)
+
+	const (
+		traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+		traceparent = "00-" + traceID + "-00f067aa0ba902b7-01"
+	)
+
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		req      = newTestRequest(t, recorder)
+		rw       = httptest.NewRecorder()
+		inReq    map[string]any
+	)
+
+	req.Header.Set("traceparent", traceparent)
+	req.Header.Set(RequestIDHeader, "rid")
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inReq = clues.In(r.Context()).Map()
+	}))
+
+	handler.ServeHTTP(rw, req)
+
+	assert.Equal(t, "rid", inReq[cluerr.RequestIDKey])
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+
+	span := ended[0]
+	assert.Equal(t, http.MethodGet, span.Name())
+	assert.Contains(t, span.Attributes(), semconv.URLPath("/path"))
+	assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+}
+
+func TestMiddleware_routePattern(t *testing.T) {
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		req      = newTestRequest(t, recorder)
+		rw       = httptest.NewRecorder()
+		mux      = http.NewServeMux()
+	)
+
+	mux.HandleFunc("GET /{name}", func(w http.ResponseWriter, r *http.Request) {})
+
+	Middleware(mux).ServeHTTP(rw, req)
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+
+	assert.Equal(t, "GET /{name}", ended[0].Name())
+	assert.Contains(t, ended[0].Attributes(), semconv.URLPath("/path"))
+}
+
+func TestMiddleware_generatesRequestID(t *testing.T) {
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		req      = newTestRequest(t, recorder)
+		rw       = httptest.NewRecorder()
+		inReq    map[string]any
+	)
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inReq = clues.In(r.Context()).Map()
+	}))
+
+	handler.ServeHTTP(rw, req)
+
+	assert.NotEmpty(t, inReq[cluerr.RequestIDKey])
+	assert.Len(t, recorder.Ended(), 1)
+}
+
+func TestMiddleware_panic(t *testing.T) {
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		req      = newTestRequest(t, recorder)
+		rw       = httptest.NewRecorder()
+	)
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("oh no")
+	}))
+
+	assert.PanicsWithValue(t, "oh no", func() {
+		handler.ServeHTTP(rw, req)
+	})
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+
+	assert.Equal(t, codes.Error, ended[0].Status().Code)
+	assert.Equal(t, "oh no", ended[0].Status().Description)
+}
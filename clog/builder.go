@@ -4,12 +4,17 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
+	"sync/atomic"
+	"time"
 
 	"github.com/alcionai/clues"
 	"github.com/alcionai/clues/cluerr"
 	"github.com/alcionai/clues/internal/node"
 	"github.com/alcionai/clues/internal/stringify"
+	"go.opentelemetry.io/otel/attribute"
 	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
 )
@@ -31,18 +36,23 @@ type builder struct {
 	labels          map[string]struct{}
 	comments        map[string]struct{}
 	skipCallerJumps int
+	ctxComments     bool
+	countErrors     bool
+	allDebug        bool
 }
 
 func newBuilder(ctx context.Context) *builder {
 	clgr, _ := fromCtx(ctx)
 
 	return &builder{
-		ctx:      ctx,
-		otel:     clgr.otel,
-		zsl:      clgr.zsl,
-		with:     map[any]any{},
-		labels:   map[string]struct{}{},
-		comments: map[string]struct{}{},
+		ctx:         ctx,
+		otel:        clgr.otel,
+		zsl:         clgr.zsl,
+		with:        map[any]any{},
+		labels:      map[string]struct{}{},
+		comments:    map[string]struct{}{},
+		countErrors: clgr.set.CountErrorLogs,
+		allDebug:    clgr.allDebug,
 	}
 }
 
@@ -67,16 +77,47 @@ func (b builder) log(l logLevel, msg string) {
 	record.SetBody(otellog.StringValue(msg))
 	record.SetSeverity(convertLevel(l))
 
-	// attach the error and its labels
+	// default fields only fill in keys that aren't otherwise set.
+	for k, v := range cluesNode.LogDefaultsMap() {
+		_, inClues := cv[k]
+		_, inWith := b.with[k]
+
+		if !inClues && !inWith {
+			cv[k] = v
+		}
+	}
+
+	// drop any clues values and defaults that aren't in the allowlist
+	filterByAllowlist(cv)
+
+	var errLabels map[string]struct{}
+
+	// attach the error, its values, and its labels.  error values override
+	// context values and defaults, and are also subject to the allowlist.
 	if b.err != nil {
-		// error values should override context values.
-		maps.Copy(cv, cluerr.CluesIn(b.err).Map())
+		for k, v := range cluerr.CluesIn(b.err).Map() {
+			if isAllowed(k) {
+				cv[k] = v
+			}
+		}
 
 		cv["error"] = b.err
 
-		labels := cluerr.Labels(b.err)
-		if len(labels) > 0 {
-			cv["error_labels"] = cluerr.Labels(b.err)
+		errLabels = cluerr.Labels(b.err)
+		if len(errLabels) > 0 {
+			cv[cluerr.LabelsAttrKey] = errLabels
+		}
+
+		if code := cluerr.Code(b.err); len(code) > 0 {
+			cv["code"] = code
+		}
+
+		if fp := cluerr.Fingerprint(b.err); len(fp) > 0 {
+			cv["fingerprint"] = fp
+		}
+
+		if count, ok := cluerr.Count(b.err); ok {
+			cv["count"] = count
 		}
 	}
 
@@ -89,20 +130,37 @@ func (b builder) log(l logLevel, msg string) {
 		cv["clog_comments"] = maps.Keys(b.comments)
 	}
 
+	// attach the ctx comment history, if requested
+	if b.ctxComments {
+		if cmts := cluesNode.Comments(); len(cmts) > 0 {
+			cv["ctx_comments"] = cmts
+		}
+	}
+
 	if b.skipCallerJumps > 0 {
 		zsl = zsl.WithOptions(zap.AddCallerSkip(b.skipCallerJumps))
 	}
 
+	fields := make(map[string]any, len(cv)+len(b.with))
+
 	// add all values collected in the map
 	for k, v := range cv {
+		fields[k] = v
 		zsl = zsl.With(k, v)
 
+		// labels use the same encoding as cluerr.AsOTELRecord.
+		if k == cluerr.LabelsAttrKey && len(errLabels) > 0 {
+			record.AddAttributes(cluerr.LabelsAttr(errLabels))
+			continue
+		}
+
 		attr := node.NewAttribute(k, v)
 		record.AddAttributes(attr.KV())
 	}
 
 	// plus any values added using builder.With()
 	for k, v := range b.with {
+		fields[stringify.Fmt(k)[0]] = v
 		zsl = zsl.With(k, v)
 
 		attr := node.NewAttribute(stringify.Fmt(k)[0], v)
@@ -112,12 +170,21 @@ func (b builder) log(l logLevel, msg string) {
 	// then write everything to the logger
 	switch l {
 	case LevelDebug:
-		var ok bool
+		// an explicit sampling decision in the ctx takes priority
+		// over label filtering.
+		ok, decided := cluesNode.IsSampled()
 
-		for _, l := range cloggerton.set.OnlyLogDebugIfContainsLabel {
-			if _, match := b.labels[l]; match {
-				ok = true
-				break
+		// test loggers capture every debug log.
+		if b.allDebug {
+			ok, decided = true, true
+		}
+
+		if !decided {
+			for _, l := range cloggerton.set.OnlyLogDebugIfContainsLabel {
+				if _, match := b.labels[l]; match {
+					ok = true
+					break
+				}
 			}
 		}
 
@@ -130,6 +197,20 @@ func (b builder) log(l logLevel, msg string) {
 		zsl.Info(msg)
 	case LevelError:
 		zsl.Error(msg)
+
+		if b.countErrors {
+			countErrorLog(b.ctx, cluesNode, b.err)
+		}
+	}
+
+	// sinks only receive the logs that the zap logger's level allows.
+	if zsl.Desugar().Core().Enabled(toZapLevel(l)) {
+		writeToSinks(b.ctx, Entry{
+			Level:  l,
+			Time:   time.Now(),
+			Msg:    msg,
+			Fields: fields,
+		})
 	}
 
 	// add otel logging if provided
@@ -144,6 +225,92 @@ func (b builder) log(l logLevel, msg string) {
 	}
 }
 
+// ErrorLogMetricID is the id of the otel counter which gets incremented by
+// each error log when Settings.CountErrorLogs is enabled.
+const ErrorLogMetricID = "clog.errors"
+
+// countErrorLog increments the error log counter, attributed with the
+// sorted labels of the error, if any.  No-ops if otel wasn't initialized.
+func countErrorLog(ctx context.Context, cluesNode *node.Node, err error) {
+	meter := cluesNode.OTELMeter()
+	if meter == nil {
+		return
+	}
+
+	counter, cerr := meter.Int64Counter(ErrorLogMetricID)
+	if cerr != nil {
+		return
+	}
+
+	labels := maps.Keys(cluerr.Labels(err))
+	slices.Sort(labels)
+
+	counter.Add(
+		ctx,
+		1,
+		metric.WithAttributes(attribute.StringSlice("error_labels", labels)))
+}
+
+// ------------------------------------------------------------------------------------------------
+// key allowlisting
+// ------------------------------------------------------------------------------------------------
+
+// keyAllowlist holds the allowlisted keys.  A nil pointer means no
+// allowlist is set.  The map is never modified once stored.
+var keyAllowlist atomic.Pointer[map[string]struct{}]
+
+// SetKeyAllowlist restricts the clues values included in each log to
+// those with a key in the allowlist.  Values from the ctx, defaults added
+// with clog.With, and the values of any attached error all get filtered.
+// Fields added by clog itself (such as "error" and "error_labels") and
+// values added with builder.With() are always included.
+//
+// This is useful for controlling log storage costs when the ctx holds
+// high-cardinality values, without changing what's stored in the ctx.
+// Calling SetKeyAllowlist with no keys removes the allowlist.
+func SetKeyAllowlist(keys ...string) {
+	if len(keys) == 0 {
+		keyAllowlist.Store(nil)
+		return
+	}
+
+	allowlist := make(map[string]struct{}, len(keys))
+
+	for _, k := range keys {
+		allowlist[k] = struct{}{}
+	}
+
+	keyAllowlist.Store(&allowlist)
+}
+
+// filterByAllowlist deletes every key in the map which doesn't appear in
+// the allowlist.  No-ops if no allowlist is set.
+func filterByAllowlist(m map[string]any) {
+	allowlist := keyAllowlist.Load()
+	if allowlist == nil {
+		return
+	}
+
+	for k := range m {
+		if _, ok := (*allowlist)[k]; !ok {
+			delete(m, k)
+		}
+	}
+}
+
+// isAllowed returns true if the key appears in the allowlist, or if no
+// allowlist is set.
+func isAllowed(k string) bool {
+	allowlist := keyAllowlist.Load()
+	if allowlist == nil {
+		return true
+	}
+
+	_, ok := (*allowlist)[k]
+
+	return ok
+}
+
 // Err attaches the error to the builder.
 // When logged, the error will be parsed for any clues parts
 // and those values will get added to the resulting log.
@@ -191,6 +358,15 @@ func (b *builder) Comment(cmnt string) *builder {
 	return b
 }
 
+// IncludeCtxComments attaches the comment history from the ctx (see
+// clues.AddComment) to the log as the "ctx_comments" field.  Comments
+// are excluded by default to keep logs lean, so this is best saved
+// for deep debugging.
+func (b *builder) IncludeCtxComments() *builder {
+	b.ctxComments = true
+	return b
+}
+
 // SkipCaller allows the logger to set its stackTrace N levels back from the
 // current call.  This is great for helper functions that handle log actions
 // which get used by many different consumers, as it will always report the
@@ -232,13 +408,16 @@ func (b *builder) With(vs ...any) *builder {
 
 	for i := 0; i < len(vs); i += 2 {
 		k := vs[i]
-		var v any
 
-		if (i + 1) < len(vs) {
-			v = vs[i+1]
+		if (i + 1) >= len(vs) {
+			if v, ok := stringify.DanglingValue(); ok {
+				b.with[k] = getValue(v)
+			}
+
+			break
 		}
 
-		b.with[k] = getValue(v)
+		b.with[k] = getValue(vs[i+1])
 	}
 
 	return b
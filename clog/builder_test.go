@@ -2,10 +2,16 @@ package clog
 
 import (
 	"context"
+	"strings"
 	"testing"
 
+	"github.com/alcionai/clues"
 	"github.com/alcionai/clues/cluerr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestBuilder(t *testing.T) {
@@ -212,3 +218,220 @@ func TestGetValue(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilder_debugSampling(t *testing.T) {
+	table := []struct {
+		name   string
+		ctx    func(ctx context.Context) context.Context
+		labels []string
+		expect int
+	}{
+		{
+			name:   "no decision, unlabeled",
+			ctx:    func(ctx context.Context) context.Context { return ctx },
+			expect: 0,
+		},
+		{
+			name: "sampled",
+			ctx: func(ctx context.Context) context.Context {
+				return clues.SetSampled(ctx, true)
+			},
+			expect: 1,
+		},
+		{
+			name: "not sampled",
+			ctx: func(ctx context.Context) context.Context {
+				return clues.SetSampled(ctx, false)
+			},
+			expect: 0,
+		},
+		{
+			name: "not sampled, labeled",
+			ctx: func(ctx context.Context) context.Context {
+				return clues.SetSampled(ctx, false)
+			},
+			labels: []string{"sampled-label"},
+			expect: 0,
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			Init(context.Background(), Settings{}.EnsureDefaults())
+
+			core, logs := observer.New(zapcore.DebugLevel)
+
+			ctx := PlantLogger(context.Background(), zap.New(core).Sugar())
+			ctx = test.ctx(ctx)
+
+			Ctx(ctx).Label(test.labels...).Debug("a debug log")
+			assert.Equal(t, test.expect, logs.Len())
+		})
+	}
+}
+
+func TestBuilder_keyAllowlist(t *testing.T) {
+	table := []struct {
+		name      string
+		allowlist []string
+		expect    []string
+		expectNot []string
+	}{
+		{
+			name:   "no allowlist",
+			expect: []string{"keep", "drop", "err_keep", "err_drop", "with", "error", "error_labels"},
+		},
+		{
+			name:      "allowlist",
+			allowlist: []string{"keep", "err_keep"},
+			expect:    []string{"keep", "err_keep", "with", "error", "error_labels"},
+			expectNot: []string{"drop", "err_drop"},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			SetKeyAllowlist(test.allowlist...)
+			defer SetKeyAllowlist()
+
+			core, logs := observer.New(zapcore.DebugLevel)
+
+			ctx := PlantLogger(context.Background(), zap.New(core).Sugar())
+			ctx = clues.Add(ctx, "keep", "v", "drop", "v")
+
+			err := cluerr.New("an error").
+				With("err_keep", "v", "err_drop", "v").
+				Label("l")
+
+			CtxErr(ctx, err).With("with", "v").Info("a log")
+			require.Equal(t, 1, logs.Len())
+
+			fields := logs.All()[0].ContextMap()
+
+			for _, k := range test.expect {
+				assert.Contains(t, fields, k)
+			}
+
+			for _, k := range test.expectNot {
+				assert.NotContains(t, fields, k)
+			}
+		})
+	}
+}
+
+func TestBuilder_keyAllowlistWithDefaults(t *testing.T) {
+	SetKeyAllowlist("keep_default")
+	defer SetKeyAllowlist()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	ctx := PlantLogger(context.Background(), zap.New(core).Sugar())
+	ctx = With(ctx, "keep_default", "v", "drop_default", "v")
+
+	Ctx(ctx).Info("a log")
+	require.Equal(t, 1, logs.Len())
+
+	fields := logs.All()[0].ContextMap()
+	assert.Contains(t, fields, "keep_default")
+	assert.NotContains(t, fields, "drop_default")
+}
+
+func TestBuilder_errCode(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := PlantLogger(context.Background(), zap.New(core).Sugar())
+
+	err := cluerr.Wrap(cluerr.New("inner").WithCode("inner_code"), "outer").
+		WithCode("outer_code")
+
+	CtxErr(ctx, err).Info("a log")
+	CtxErr(ctx, cluerr.New("no code")).Info("another log")
+	require.Equal(t, 2, logs.Len())
+
+	assert.Equal(t, "outer_code", logs.All()[0].ContextMap()["code"])
+	assert.NotContains(t, logs.All()[1].ContextMap(), "code")
+}
+
+func TestBuilder_errFingerprint(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := PlantLogger(context.Background(), zap.New(core).Sugar())
+
+	err := cluerr.Wrap(cluerr.New("inner").WithFingerprint("inner"), "outer").
+		WithFingerprint("outer", "fp")
+
+	CtxErr(ctx, err).Info("a log")
+	CtxErr(ctx, cluerr.New("no fingerprint")).Info("another log")
+	require.Equal(t, 2, logs.Len())
+
+	assert.Equal(t, []any{"outer", "fp"}, logs.All()[0].ContextMap()["fingerprint"])
+	assert.NotContains(t, logs.All()[1].ContextMap(), "fingerprint")
+}
+
+func TestBuilder_errCount(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := PlantLogger(context.Background(), zap.New(core).Sugar())
+
+	CtxErr(ctx, cluerr.New("err").WithCount(37)).Info("a log")
+	CtxErr(ctx, cluerr.New("no count")).Info("another log")
+	require.Equal(t, 2, logs.Len())
+
+	assert.EqualValues(t, 37, logs.All()[0].ContextMap()["count"])
+	assert.NotContains(t, logs.All()[1].ContextMap(), "count")
+}
+
+func TestBuilder_includeCtxComments(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	base := PlantLogger(context.Background(), zap.New(core).Sugar())
+
+	ctx := clues.AddComment(base, "first comment")
+	ctx = clues.AddComment(ctx, "second comment")
+
+	Ctx(ctx).Info("without comments")
+	Ctx(ctx).IncludeCtxComments().Info("with comments")
+	Ctx(base).IncludeCtxComments().Info("no ctx comments")
+	require.Equal(t, 3, logs.Len())
+
+	assert.NotContains(t, logs.All()[0].ContextMap(), "ctx_comments")
+
+	cmts, ok := logs.All()[1].ContextMap()["ctx_comments"].(string)
+	require.True(t, ok, "comments should be stringified")
+	assert.Contains(t, cmts, "first comment")
+	assert.Contains(t, cmts, "second comment")
+	assert.Less(
+		t,
+		strings.Index(cmts, "first comment"),
+		strings.Index(cmts, "second comment"),
+		"comments are ordered oldest first")
+
+	assert.NotContains(t, logs.All()[2].ContextMap(), "ctx_comments")
+}
+
+func TestBuilder_With_oddArgsPolicy(t *testing.T) {
+	table := []struct {
+		name   string
+		policy clues.OddArgsPolicy
+		expect map[any]any
+	}{
+		{
+			name:   "nil",
+			policy: clues.OddArgsNil,
+			expect: map[any]any{"k": "v", "dangling": nil},
+		},
+		{
+			name:   "drop",
+			policy: clues.OddArgsDrop,
+			expect: map[any]any{"k": "v"},
+		},
+		{
+			name:   "missing",
+			policy: clues.OddArgsMissing,
+			expect: map[any]any{"k": "v", "dangling": "<missing>"},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			clues.SetOddArgsPolicy(test.policy)
+			defer clues.SetOddArgsPolicy(clues.OddArgsNil)
+
+			bld := newBuilder(context.Background()).With("k", "v", "dangling")
+			assert.Equal(t, test.expect, bld.with)
+		})
+	}
+}
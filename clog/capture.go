@@ -0,0 +1,181 @@
+package clog
+
+import (
+	"context"
+	"sync"
+
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// ------------------------------------------------------------------------------------------------
+// test logger
+// ------------------------------------------------------------------------------------------------
+
+// CapturedLog is a single log emission recorded by a test logger.
+type CapturedLog struct {
+	Level  logLevel
+	Msg    string
+	Fields map[string]any
+}
+
+// CapturedLogs records every log emitted through a test logger.  Logs
+// are recorded separately for the zap path and the otel path, so that
+// tests can assert on both sinks.
+type CapturedLogs struct {
+	zapLogs *observer.ObservedLogs
+
+	mu       sync.Mutex
+	otelLogs []CapturedLog
+}
+
+// NewTestLogger embeds a logger in the ctx which captures every log
+// instead of writing it out.  Nothing is sent to a real otel endpoint.
+// Use the returned CapturedLogs to assert on the emitted logs.
+//
+// Debug logs are captured without needing a debug label.  The ctx's
+// sampling decision (see clues.SetSampled) is left unchanged, so spans
+// behave the same as they do in production.
+func NewTestLogger(ctx context.Context) (context.Context, *CapturedLogs) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var (
+		core, logs = observer.New(zapcore.DebugLevel)
+		captured   = &CapturedLogs{zapLogs: logs}
+	)
+
+	clgr := &clogger{
+		otel:     &capturingOTELLogger{captured: captured},
+		zsl:      zap.New(core).Sugar(),
+		set:      Settings{Level: LevelDebug}.EnsureDefaults(),
+		allDebug: true,
+	}
+
+	return plantLoggerInCtx(ctx, clgr), captured
+}
+
+// Zap returns every log emitted through the zap logger, in order.
+func (cl *CapturedLogs) Zap() []CapturedLog {
+	entries := cl.zapLogs.All()
+	logs := make([]CapturedLog, 0, len(entries))
+
+	for _, e := range entries {
+		logs = append(logs, CapturedLog{
+			Level:  fromZapLevel(e.Level),
+			Msg:    e.Message,
+			Fields: e.ContextMap(),
+		})
+	}
+
+	return logs
+}
+
+// OTEL returns every log emitted through the otel logger, in order.
+func (cl *CapturedLogs) OTEL() []CapturedLog {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return append([]CapturedLog{}, cl.otelLogs...)
+}
+
+// fromZapLevel converts a zapcore level into the clog level.
+func fromZapLevel(level zapcore.Level) logLevel {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return LevelDebug
+	case level == zapcore.InfoLevel:
+		return LevelInfo
+	default:
+		return LevelError
+	}
+}
+
+// toZapLevel converts the clog level into the zapcore level.
+func toZapLevel(level logLevel) zapcore.Level {
+	switch level {
+	case LevelDebug:
+		return zapcore.DebugLevel
+	case LevelInfo:
+		return zapcore.InfoLevel
+	case LevelError:
+		return zapcore.ErrorLevel
+	default:
+		// matches the level used to disable the zap logger.
+		return zapcore.FatalLevel
+	}
+}
+
+// fromOTELSeverity converts an otel severity into the clog level.
+func fromOTELSeverity(severity otellog.Severity) logLevel {
+	switch {
+	case severity == otellog.SeverityUndefined:
+		return LevelDisabled
+	case severity < otellog.SeverityInfo:
+		return LevelDebug
+	case severity < otellog.SeverityWarn:
+		return LevelInfo
+	default:
+		return LevelError
+	}
+}
+
+// fromOTELValue converts an otel log value into its go equivalent.
+func fromOTELValue(v otellog.Value) any {
+	switch v.Kind() {
+	case otellog.KindString:
+		return v.AsString()
+	case otellog.KindInt64:
+		return v.AsInt64()
+	case otellog.KindBool:
+		return v.AsBool()
+	case otellog.KindFloat64:
+		return v.AsFloat64()
+	case otellog.KindEmpty:
+		return nil
+	case otellog.KindSlice:
+		vs := v.AsSlice()
+		result := make([]any, 0, len(vs))
+
+		for _, sv := range vs {
+			result = append(result, fromOTELValue(sv))
+		}
+
+		return result
+	default:
+		return v.String()
+	}
+}
+
+// capturingOTELLogger is an otel logger which records each emitted
+// record into the CapturedLogs.
+type capturingOTELLogger struct {
+	embedded.Logger
+	captured *CapturedLogs
+}
+
+func (l *capturingOTELLogger) Emit(_ context.Context, record otellog.Record) {
+	fields := map[string]any{}
+
+	record.WalkAttributes(func(kv otellog.KeyValue) bool {
+		fields[kv.Key] = fromOTELValue(kv.Value)
+		return true
+	})
+
+	l.captured.mu.Lock()
+	defer l.captured.mu.Unlock()
+
+	l.captured.otelLogs = append(l.captured.otelLogs, CapturedLog{
+		Level:  fromOTELSeverity(record.Severity()),
+		Msg:    record.Body().AsString(),
+		Fields: fields,
+	})
+}
+
+func (l *capturingOTELLogger) Enabled(context.Context, otellog.EnabledParameters) bool {
+	return true
+}
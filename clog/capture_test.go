@@ -0,0 +1,148 @@
+package clog_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alcionai/clues"
+	"github.com/alcionai/clues/clog"
+	"github.com/alcionai/clues/cluerr"
+)
+
+func TestNewTestLogger(t *testing.T) {
+	ctx, logs := clog.NewTestLogger(context.Background())
+	ctx = clues.Add(ctx, "ctx_key", "ctx_value")
+
+	clog.Ctx(ctx).
+		With("with_key", 1).
+		Debug("a debug log")
+
+	clog.CtxErr(ctx, cluerr.New("an error").Label("l")).
+		Error("an error log")
+
+	for name, captured := range map[string][]clog.CapturedLog{
+		"zap":  logs.Zap(),
+		"otel": logs.OTEL(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Len(t, captured, 2)
+
+			debug := captured[0]
+			assert.Equal(t, clog.LevelDebug, debug.Level)
+			assert.Equal(t, "a debug log", debug.Msg)
+			assert.Equal(t, "ctx_value", debug.Fields["ctx_key"])
+			assert.EqualValues(t, 1, debug.Fields["with_key"])
+
+			errLog := captured[1]
+			assert.Equal(t, clog.LevelError, errLog.Level)
+			assert.Equal(t, "an error log", errLog.Msg)
+			assert.Equal(t, "ctx_value", errLog.Fields["ctx_key"])
+			assert.Contains(t, errLog.Fields, "error")
+			assert.Contains(t, errLog.Fields, "error_labels")
+		})
+	}
+}
+
+func TestNewTestLogger_leavesSamplingUnchanged(t *testing.T) {
+	ctx, logs := clog.NewTestLogger(context.Background())
+
+	_, decided := clues.In(ctx).IsSampled()
+	assert.False(t, decided, "test logger must not decide trace sampling")
+
+	clog.Ctx(ctx).Debug("a debug log")
+
+	require.Len(t, logs.Zap(), 1)
+	assert.Equal(t, "a debug log", logs.Zap()[0].Msg)
+}
+
+func TestNewTestLogger_otelErrorRecord(t *testing.T) {
+	clog.SetKeyAllowlist("ctx_key", "err_keep")
+	defer clog.SetKeyAllowlist()
+
+	ctx, logs := clog.NewTestLogger(context.Background())
+	ctx = clues.Add(ctx, "ctx_key", "ctx_value")
+
+	err := cluerr.New("an error").
+		With("err_keep", "v", "err_drop", "v").
+		Label("b", "a")
+
+	clog.CtxErr(ctx, err).Error("an error log")
+
+	captured := logs.OTEL()
+	require.Len(t, captured, 1)
+
+	// the error attributes match the encoding used by cluerr.AsOTELRecord.
+	fields := captured[0].Fields
+	assert.Equal(t, []any{"a", "b"}, fields[cluerr.LabelsAttrKey])
+	assert.Equal(t, "v", fields["err_keep"])
+	assert.NotContains(t, fields, "err_drop")
+	assert.Equal(t, "ctx_value", fields["ctx_key"])
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	table := []struct {
+		name      string
+		panicWith any
+		expectErr string
+	}{
+		{
+			name:      "value",
+			panicWith: "oh no",
+			expectErr: "recovered panic: oh no",
+		},
+		{
+			name:      "error",
+			panicWith: cluerr.New("oh no"),
+			expectErr: "recovered panic: oh no",
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, logs := clog.NewTestLogger(context.Background())
+			ctx = clues.Add(ctx, "ctx_key", "ctx_value")
+
+			assert.PanicsWithValue(t, test.panicWith, func() {
+				defer clog.RecoverAndLog(ctx)
+				panic(test.panicWith)
+			})
+
+			captured := logs.Zap()
+			require.Len(t, captured, 1)
+
+			l := captured[0]
+			assert.Equal(t, clog.LevelError, l.Level)
+			assert.Equal(t, "recovered panic", l.Msg)
+			assert.Equal(t, test.expectErr, l.Fields["error"])
+			assert.Equal(t, "ctx_value", l.Fields["ctx_key"])
+			assert.Contains(t, l.Fields["stack"], "TestRecoverAndLog")
+		})
+	}
+
+	ctx, logs := clog.NewTestLogger(context.Background())
+
+	assert.NotPanics(t, func() {
+		defer clog.RecoverAndLog(ctx)
+	})
+	assert.Empty(t, logs.Zap(), "nothing is logged without a panic")
+}
+
+func TestWith_scoped(t *testing.T) {
+	ctx, logs := clog.NewTestLogger(context.Background())
+	ctx = clog.With(ctx, "global_default", "g")
+
+	a := clog.With(clues.Scope(ctx, "tenantA"), "a_default", "a")
+	b := clues.Scope(a, "tenantB")
+
+	clog.Ctx(a).Info("tenant a")
+	clog.Ctx(b).Info("tenant b")
+
+	captured := logs.Zap()
+	require.Len(t, captured, 2)
+
+	assert.Equal(t, "a", captured[0].Fields["a_default"])
+	assert.Equal(t, "g", captured[1].Fields["global_default"])
+	assert.NotContains(t, captured[1].Fields, "a_default")
+}
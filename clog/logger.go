@@ -3,7 +3,9 @@ package clog
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
+	"runtime/debug"
 	"sync"
 	"time"
 
@@ -12,6 +14,9 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	"github.com/alcionai/clues"
+	"github.com/alcionai/clues/cluerr"
+	"github.com/alcionai/clues/internal/node"
+	"github.com/alcionai/clues/internal/stringify"
 )
 
 // Yes, we just hijack zap for our logging needs here.
@@ -26,6 +31,8 @@ type clogger struct {
 	otel log.Logger
 	zsl  *zap.SugaredLogger
 	set  Settings
+	// allDebug skips the debug log filtering.  Only test loggers set it.
+	allDebug bool
 }
 
 // ---------------------------------------------------------------------------
@@ -69,15 +76,9 @@ func genLogger(set Settings) *zap.SugaredLogger {
 		// separated values within each line, and may contain multiple json objs.
 	default:
 		zcfg = setLevel(zap.NewDevelopmentConfig(), set.Level)
-
-		zcfg.EncoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout(time.StampMilli)
-
-		// when printing to stdout/stderr, colorize things!
-		if toFile == Stderr || toFile == Stdout {
-			zcfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-		}
 	}
 
+	zcfg.EncoderConfig = encoderConfig(set, toFile)
 	zcfg.OutputPaths = []string{toFile}
 
 	zlog, err := zcfg.Build(zopts...)
@@ -89,6 +90,42 @@ func genLogger(set Settings) *zap.SugaredLogger {
 	return zlog.Sugar()
 }
 
+// encoderConfig produces the zap encoder config for the format, writing
+// to the given file.
+func encoderConfig(set Settings, toFile string) zapcore.EncoderConfig {
+	if set.Format == FormatToJSON {
+		return zap.NewProductionEncoderConfig()
+	}
+
+	ecfg := zap.NewDevelopmentEncoderConfig()
+	ecfg.EncodeTime = zapcore.TimeEncoderOfLayout(time.StampMilli)
+
+	// debug is magenta, info is blue, error is red.
+	switch set.Color {
+	case ColorAlways:
+		ecfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	case ColorNever:
+		ecfg.EncodeLevel = zapcore.CapitalLevelEncoder
+	default:
+		// when printing to stdout/stderr, colorize things!
+		if toFile == Stderr || toFile == Stdout {
+			ecfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+	}
+
+	return ecfg
+}
+
+// newEncoder produces the zap encoder for the format, writing to the
+// given file.
+func newEncoder(set Settings, toFile string) zapcore.Encoder {
+	if set.Format == FormatToJSON {
+		return zapcore.NewJSONEncoder(encoderConfig(set, toFile))
+	}
+
+	return zapcore.NewConsoleEncoder(encoderConfig(set, toFile))
+}
+
 // set up a logger core to use as a fallback in case the config doesn't work.
 // we shouldn't ever need this, but it's nice to know there's a fallback in
 // case configuration gets buggery, because everyone still wants their logs.
@@ -109,9 +146,9 @@ func zapcoreFallback(set Settings) *zap.Logger {
 
 	// build out the zapcore fallback
 	var (
-		out            = zapcore.Lock(os.Stderr)
-		consoleEncoder = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
-		core           = zapcore.NewTee(zapcore.NewCore(consoleEncoder, out, levelFilter))
+		out     = zapcore.Lock(os.Stderr)
+		encoder = newEncoder(set, Stderr)
+		core    = zapcore.NewTee(zapcore.NewCore(encoder, out, levelFilter))
 	)
 
 	return zap.New(core)
@@ -234,6 +271,49 @@ func CtxErr(ctx context.Context, err error) *builder {
 	return nb
 }
 
+// RecoverAndLog recovers from a panic, logs it at the error level, and then
+// re-panics with the original value.  This standardizes crash logging:
+//
+//	defer clog.RecoverAndLog(ctx)
+//
+// The logged error is built from the ctx clues, wraps the panic value (if
+// the value is an error), and includes the stack trace of the panic under
+// the "stack" key.  Must be deferred directly; calling it from within
+// another deferred func won't recover the panic.
+func RecoverAndLog(ctx context.Context) {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+
+	var err *cluerr.Err
+
+	if recErr, ok := rec.(error); ok {
+		err = cluerr.WrapWC(ctx, recErr, "recovered panic")
+	} else {
+		err = cluerr.NewWC(ctx, fmt.Sprintf("recovered panic: %v", rec))
+	}
+
+	CtxErr(ctx, err).
+		With("stack", string(debug.Stack())).
+		Error("recovered panic")
+
+	panic(rec)
+}
+
+// With embeds the key:value pairs in the ctx as default fields, which get
+// included in every log produced with the returned ctx and its descendants,
+// such as ctxs produced by clues.AddSpan.  Default fields have the lowest
+// priority: clues values, error values, and values added with builder.With()
+// all override a default field with the same key.
+//
+// Unlike clues.Add, default fields are only added to logs.  They are not
+// attached to errors or spans.
+func With(ctx context.Context, kvs ...any) context.Context {
+	nc := node.FromCtx(ctx)
+	return node.EmbedInCtx(ctx, nc.AddLogDefaults(stringify.Normalize(kvs...)))
+}
+
 // Singleton is a shorthand for .Ctx(context.Background()).  IE: it'll use the singleton
 // logger directly; building one if necessary.  You should avoid this and use .Ctx or
 // .CtxErr if possible.  Likelihood is that you're somewhere deep in a func chain that
@@ -261,6 +341,17 @@ func Flush(ctx context.Context) {
 	_ = Ctx(ctx).zsl.Sync()
 }
 
+// Shutdown flushes all buffered logs, and then closes the clues otel
+// client (see clues.Close), which flushes and shuts down the otel
+// providers.  Logs are always drained before the providers shut down,
+// so that no logs are lost at exit.  Should be called in a defer after
+// initializing, in place of calling clues.Close directly.
+func Shutdown(ctx context.Context) error {
+	Flush(ctx)
+
+	return clues.Close(ctx)
+}
+
 // Inherit propagates the clog client from one context to another.  This is particularly
 // useful for taking an initialized context from a main() func and ensuring the logger
 // is available for request-bound conetxts, such as in a http server pattern.
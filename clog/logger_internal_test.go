@@ -2,10 +2,21 @@ package clog
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdkMetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/alcionai/clues"
+	"github.com/alcionai/clues/cluerr"
+	"github.com/alcionai/clues/internal/node"
 )
 
 func TestInherit(t *testing.T) {
@@ -155,3 +166,180 @@ func TestInherit(t *testing.T) {
 		})
 	}
 }
+
+func TestNewEncoder(t *testing.T) {
+	table := []struct {
+		name   string
+		set    Settings
+		toFile string
+		expect map[zapcore.Level]string
+	}{
+		{
+			name:   "human, auto, stderr",
+			set:    Settings{Format: FormatForHumans, Color: ColorAuto},
+			toFile: Stderr,
+			expect: map[zapcore.Level]string{
+				zapcore.DebugLevel: "\x1b[35mDEBUG\x1b[0m",
+				zapcore.InfoLevel:  "\x1b[34mINFO\x1b[0m",
+				zapcore.ErrorLevel: "\x1b[31mERROR\x1b[0m",
+			},
+		},
+		{
+			name:   "human, auto, file",
+			set:    Settings{Format: FormatForHumans, Color: ColorAuto},
+			toFile: "/tmp/log.log",
+			expect: map[zapcore.Level]string{
+				zapcore.DebugLevel: "DEBUG\tmsg",
+				zapcore.InfoLevel:  "INFO\tmsg",
+				zapcore.ErrorLevel: "ERROR\tmsg",
+			},
+		},
+		{
+			name:   "human, always, file",
+			set:    Settings{Format: FormatForHumans, Color: ColorAlways},
+			toFile: "/tmp/log.log",
+			expect: map[zapcore.Level]string{
+				zapcore.DebugLevel: "\x1b[35mDEBUG\x1b[0m",
+				zapcore.InfoLevel:  "\x1b[34mINFO\x1b[0m",
+				zapcore.ErrorLevel: "\x1b[31mERROR\x1b[0m",
+			},
+		},
+		{
+			name:   "human, never, stdout",
+			set:    Settings{Format: FormatForHumans, Color: ColorNever},
+			toFile: Stdout,
+			expect: map[zapcore.Level]string{
+				zapcore.DebugLevel: "DEBUG\tmsg",
+				zapcore.InfoLevel:  "INFO\tmsg",
+				zapcore.ErrorLevel: "ERROR\tmsg",
+			},
+		},
+		{
+			name:   "json, always, stderr",
+			set:    Settings{Format: FormatToJSON, Color: ColorAlways},
+			toFile: Stderr,
+			expect: map[zapcore.Level]string{
+				zapcore.DebugLevel: `"level":"debug"`,
+				zapcore.InfoLevel:  `"level":"info"`,
+				zapcore.ErrorLevel: `"level":"error"`,
+			},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			enc := newEncoder(test.set, test.toFile)
+
+			for level, token := range test.expect {
+				buf, err := enc.EncodeEntry(zapcore.Entry{Level: level, Message: "msg"}, nil)
+				require.NoError(t, err)
+
+				assert.Contains(t, buf.String(), token)
+			}
+		})
+	}
+}
+
+func TestWith_inheritedBySpans(t *testing.T) {
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder))
+		root     = &node.Node{OTEL: &node.OTELClient{Tracer: provider.Tracer("test")}}
+		ctx      = node.EmbedInCtx(context.Background(), root)
+	)
+
+	ctx, logs := NewTestLogger(ctx)
+	ctx = With(ctx, "component", "db", "overridden", "default")
+
+	spanCtx := clues.AddSpan(ctx, "span")
+	require.NotNil(t, node.FromCtx(spanCtx).Span, "ctx should hold a span")
+
+	spanCtx = clues.Add(spanCtx, "overridden", "clues")
+	spanCtx = With(spanCtx, "nested", "n")
+
+	Ctx(spanCtx).Info("inside the span")
+	Ctx(ctx).Info("outside the span")
+
+	clues.CloseSpan(spanCtx)
+
+	captured := logs.Zap()
+	require.Len(t, captured, 2)
+
+	inside := captured[0].Fields
+	assert.Equal(t, "db", inside["component"])
+	assert.Equal(t, "clues", inside["overridden"], "clues values override defaults")
+	assert.Equal(t, "n", inside["nested"])
+
+	outside := captured[1].Fields
+	assert.Equal(t, "db", outside["component"])
+	assert.Equal(t, "default", outside["overridden"])
+	assert.NotContains(t, outside, "nested")
+
+	// defaults don't leak into spans or the clues values.
+	assert.NotContains(t, clues.In(spanCtx).Map(), "component")
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+
+	for _, attr := range ended[0].Attributes() {
+		assert.NotEqual(t, "component", string(attr.Key))
+	}
+}
+
+func TestCountErrorLogs(t *testing.T) {
+	var (
+		reader   = sdkMetric.NewManualReader()
+		provider = sdkMetric.NewMeterProvider(sdkMetric.WithReader(reader))
+		root     = &node.Node{OTEL: &node.OTELClient{Meter: provider.Meter("test")}}
+		ctx      = node.EmbedInCtx(context.Background(), root)
+		set      = Settings{Level: LevelDebug, CountErrorLogs: true}.EnsureDefaults()
+	)
+
+	ctx = plantLoggerInCtx(ctx, &clogger{zsl: zap.NewNop().Sugar(), set: set})
+
+	Ctx(ctx).Info("info")
+	Ctx(ctx).Debug("debug")
+
+	collected := metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(ctx, &collected))
+	assert.Empty(t, collected.ScopeMetrics, "non-error logs are not counted")
+
+	CtxErr(ctx, cluerr.New("err").Label("b", "a")).Error("labeled")
+	CtxErr(ctx, cluerr.New("err").Label("b", "a")).Error("labeled again")
+	Ctx(ctx).Error("unlabeled")
+
+	require.NoError(t, reader.Collect(ctx, &collected))
+	require.Len(t, collected.ScopeMetrics, 1)
+	require.Len(t, collected.ScopeMetrics[0].Metrics, 1)
+
+	m := collected.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, ErrorLogMetricID, m.Name)
+
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	require.True(t, ok, "metric is an int64 sum")
+
+	counts := map[string]int64{}
+
+	for _, dp := range sum.DataPoints {
+		v, _ := dp.Attributes.Value("error_labels")
+		counts[strings.Join(v.AsStringSlice(), ",")] = dp.Value
+	}
+
+	assert.Equal(t, map[string]int64{"a,b": 2, "": 1}, counts)
+}
+
+func TestCountErrorLogs_disabled(t *testing.T) {
+	var (
+		reader   = sdkMetric.NewManualReader()
+		provider = sdkMetric.NewMeterProvider(sdkMetric.WithReader(reader))
+		root     = &node.Node{OTEL: &node.OTELClient{Meter: provider.Meter("test")}}
+		ctx      = node.EmbedInCtx(context.Background(), root)
+	)
+
+	ctx = plantLoggerInCtx(ctx, &clogger{zsl: zap.NewNop().Sugar(), set: Settings{}.EnsureDefaults()})
+
+	CtxErr(ctx, cluerr.New("err")).Error("error")
+
+	collected := metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(ctx, &collected))
+	assert.Empty(t, collected.ScopeMetrics)
+}
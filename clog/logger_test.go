@@ -1,11 +1,15 @@
 package clog_test
 
 import (
+	"bytes"
+	"context"
 	"testing"
 
 	"github.com/alcionai/clues/clog"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type LoggerUnitSuite struct {
@@ -31,3 +35,23 @@ func (suite *LoggerUnitSuite) TestSettings_ensureDefaults() {
 	require.NotEmpty(t, s.SensitiveInfoHandling, "piialg")
 	require.Empty(t, s.OnlyLogDebugIfContainsLabel, "debug filter")
 }
+
+func (suite *LoggerUnitSuite) TestShutdown() {
+	t := suite.T()
+
+	var (
+		buf bytes.Buffer
+		ws  = &zapcore.BufferedWriteSyncer{WS: zapcore.AddSync(&buf)}
+		enc = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+		zl  = zap.New(zapcore.NewCore(enc, ws, zapcore.DebugLevel))
+		ctx = clog.PlantLogger(context.Background(), zl.Sugar())
+	)
+
+	defer ws.Stop()
+
+	clog.Ctx(ctx).Info("buffered log")
+	require.Empty(t, buf.String(), "log should be held in the buffer")
+
+	require.NoError(t, clog.Shutdown(ctx))
+	require.Contains(t, buf.String(), "buffered log")
+}
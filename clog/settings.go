@@ -32,6 +32,17 @@ const (
 	FormatToJSON logFormat = "json"
 )
 
+type colorMode string
+
+const (
+	// colorize log levels only when logging to stdout or stderr.
+	ColorAuto colorMode = "auto"
+	// always colorize log levels.
+	ColorAlways colorMode = "always"
+	// never colorize log levels.
+	ColorNever colorMode = "never"
+)
+
 type sensitiveInfoHandlingAlgo string
 
 const (
@@ -63,6 +74,10 @@ type Settings struct {
 	// below this level (following standard semantics) will
 	// not get logged.
 	Level logLevel
+	// Color determines whether log levels get colorized.  Only
+	// applies to the FormatForHumans console output.  JSON output
+	// is never colorized.
+	Color colorMode
 
 	// more fiddly bits
 	SensitiveInfoHandling sensitiveInfoHandlingAlgo // how to obscure pii
@@ -71,6 +86,10 @@ type Settings struct {
 	// logs get dropped.  Good way to expose a little bit of debug
 	// logs without flooding your system.
 	OnlyLogDebugIfContainsLabel []string
+	// when true, every error-level log increments the otel counter
+	// "clog.errors", attributed with the labels of the logged error.
+	// Requires otel to be initialized in clues.
+	CountErrorLogs bool
 }
 
 // LogToStdOut swaps the log output from Stderr to Stdout.
@@ -113,6 +132,11 @@ func (s Settings) EnsureDefaults() Settings {
 		set.Format = FormatForHumans
 	}
 
+	colors := []colorMode{ColorAuto, ColorAlways, ColorNever}
+	if len(set.Color) == 0 || !slices.Contains(colors, set.Color) {
+		set.Color = ColorAuto
+	}
+
 	algs := []sensitiveInfoHandlingAlgo{ShowSensitiveInfoInPlainText, MaskSensitiveInfo, HashSensitiveInfo}
 	if len(set.SensitiveInfoHandling) == 0 || !slices.Contains(algs, set.SensitiveInfoHandling) {
 		set.SensitiveInfoHandling = ShowSensitiveInfoInPlainText
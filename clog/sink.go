@@ -0,0 +1,180 @@
+package clog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alcionai/clues/internal/stringify"
+)
+
+// ------------------------------------------------------------------------------------------------
+// sinks
+// ------------------------------------------------------------------------------------------------
+
+// Entry is a single log emission, as delivered to each Sink.
+type Entry struct {
+	Level  logLevel
+	Time   time.Time
+	Msg    string
+	Fields map[string]any
+}
+
+// Sink receives every log that clog emits, independent of the zap and
+// otel loggers.  Sinks get called synchronously with each log, so they
+// should avoid blocking.
+type Sink interface {
+	Write(ctx context.Context, entry Entry)
+}
+
+var (
+	sinksMu sync.RWMutex
+	sinks   []Sink
+)
+
+// AddSink registers the sink to receive every log emitted by clog.
+func AddSink(s Sink) {
+	if s == nil {
+		return
+	}
+
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+
+	sinks = append(sinks, s)
+}
+
+// AddWriterSink registers a sink which writes each log to w as a single
+// line of json, with the "level", "ts", "msg", and "fields" properties.
+// This is handy for tests, and for deployments without an otel sidecar.
+// Field values which json can't represent natively are stringified, with
+// concealed values remaining concealed.  The registered sink is returned
+// so that it can be passed to RemoveSink.
+func AddWriterSink(w io.Writer) Sink {
+	if w == nil {
+		return nil
+	}
+
+	ws := &writerSink{w: w}
+	AddSink(ws)
+
+	return ws
+}
+
+// RemoveSink unregisters every registration of the sink.  Sinks with
+// non-comparable types can only be removed with ResetSinks.
+func RemoveSink(s Sink) {
+	if s == nil || !reflect.TypeOf(s).Comparable() {
+		return
+	}
+
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+
+	kept := make([]Sink, 0, len(sinks))
+
+	for _, registered := range sinks {
+		// comparing interfaces with the same non-comparable dynamic
+		// type panics, so the types get checked first.
+		if reflect.TypeOf(registered) == reflect.TypeOf(s) && registered == s {
+			continue
+		}
+
+		kept = append(kept, registered)
+	}
+
+	sinks = kept
+}
+
+// ResetSinks unregisters all sinks.
+func ResetSinks() {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+
+	sinks = nil
+}
+
+// writeToSinks delivers the entry to every registered sink.  The sinks
+// are called without holding the lock, so that a sink can log, or add and
+// remove sinks, without deadlocking.
+func writeToSinks(ctx context.Context, entry Entry) {
+	sinksMu.RLock()
+	registered := sinks
+	sinksMu.RUnlock()
+
+	for _, s := range registered {
+		s.Write(ctx, entry)
+	}
+}
+
+// writerSink writes each entry to the writer as a line of json.
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+type jsonEntry struct {
+	Level  logLevel       `json:"level"`
+	Time   time.Time      `json:"ts"`
+	Msg    string         `json:"msg"`
+	Fields map[string]any `json:"fields"`
+}
+
+func (ws *writerSink) Write(_ context.Context, entry Entry) {
+	je := jsonEntry{
+		Level:  entry.Level,
+		Time:   entry.Time,
+		Msg:    entry.Msg,
+		Fields: make(map[string]any, len(entry.Fields)),
+	}
+
+	for k, v := range entry.Fields {
+		je.Fields[k] = jsonValue(v)
+	}
+
+	bs, err := json.Marshal(je)
+	if err != nil {
+		return
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	// nowhere to report a failed write, so it gets dropped.
+	_, _ = ws.w.Write(append(bs, '\n'))
+}
+
+// jsonValue retains the values which json represents natively, uses the
+// message of errors, and stringifies the rest.  Floats which json can't
+// represent (NaN and infinities) are stringified.
+func jsonValue(v any) any {
+	switch tv := v.(type) {
+	case nil, bool, string,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64:
+		return v
+	case float32:
+		return jsonFloat(float64(tv), v)
+	case float64:
+		return jsonFloat(tv, v)
+	case error:
+		return tv.Error()
+	}
+
+	return stringify.Marshal(v, true)
+}
+
+// jsonFloat returns v if json can represent the float, and otherwise
+// the float's string form.
+func jsonFloat(f float64, v any) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+
+	return v
+}
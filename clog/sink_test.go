@@ -0,0 +1,177 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/alcionai/clues"
+	"github.com/alcionai/clues/cecrets"
+	"github.com/alcionai/clues/cluerr"
+)
+
+func TestAddWriterSink(t *testing.T) {
+	defer ResetSinks()
+
+	var (
+		buf     bytes.Buffer
+		core, _ = observer.New(zapcore.DebugLevel)
+		ctx     = PlantLogger(context.Background(), zap.New(core).Sugar())
+		before  = time.Now()
+	)
+
+	AddWriterSink(&buf)
+
+	ctx = clues.Add(ctx, "ctx_key", "ctx_value")
+
+	Ctx(ctx).
+		With("with_key", 1, "secret", cecrets.Hide("shh")).
+		Info("an info log")
+	CtxErr(ctx, cluerr.New("an error")).
+		Error("an error log")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	type entry struct {
+		Level  string         `json:"level"`
+		TS     time.Time      `json:"ts"`
+		Msg    string         `json:"msg"`
+		Fields map[string]any `json:"fields"`
+	}
+
+	var info, errLog entry
+
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &info))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &errLog))
+
+	assert.Equal(t, "info", info.Level)
+	assert.Equal(t, "an info log", info.Msg)
+	assert.False(t, info.TS.Before(before), "timestamp is set")
+	assert.Equal(t, "ctx_value", info.Fields["ctx_key"])
+	assert.EqualValues(t, 1, info.Fields["with_key"])
+	assert.Equal(t, cecrets.Hide("shh").Conceal(), info.Fields["secret"], "secrets stay concealed")
+
+	assert.Equal(t, "error", errLog.Level)
+	assert.Equal(t, "an error log", errLog.Msg)
+	assert.Equal(t, "an error", errLog.Fields["error"])
+	assert.Equal(t, "ctx_value", errLog.Fields["ctx_key"])
+}
+
+func TestAddWriterSink_level(t *testing.T) {
+	defer ResetSinks()
+
+	var (
+		buf     bytes.Buffer
+		core, _ = observer.New(zapcore.InfoLevel)
+		ctx     = PlantLogger(context.Background(), zap.New(core).Sugar())
+	)
+
+	AddWriterSink(&buf)
+
+	Ctx(clues.SetSampled(ctx, true)).Debug("dropped by the level")
+	Ctx(ctx).Info("an info log")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "an info log")
+}
+
+func TestRemoveSink(t *testing.T) {
+	defer ResetSinks()
+
+	var (
+		kept, removed bytes.Buffer
+		core, _       = observer.New(zapcore.DebugLevel)
+		ctx           = PlantLogger(context.Background(), zap.New(core).Sugar())
+	)
+
+	AddWriterSink(&kept)
+	sink := AddWriterSink(&removed)
+
+	Ctx(ctx).Info("first")
+	RemoveSink(sink)
+	Ctx(ctx).Info("second")
+
+	assert.Equal(t, 2, strings.Count(kept.String(), "\n"))
+	assert.Equal(t, 1, strings.Count(removed.String(), "\n"))
+
+	ResetSinks()
+	Ctx(ctx).Info("third")
+
+	assert.Equal(t, 2, strings.Count(kept.String(), "\n"))
+}
+
+// removingSink removes itself from the registered sinks on its first write.
+type removingSink struct {
+	writes int
+}
+
+func (rs *removingSink) Write(context.Context, Entry) {
+	rs.writes++
+	RemoveSink(rs)
+}
+
+func TestWriteToSinks_reentrant(t *testing.T) {
+	defer ResetSinks()
+
+	var (
+		core, _ = observer.New(zapcore.DebugLevel)
+		ctx     = PlantLogger(context.Background(), zap.New(core).Sugar())
+		rs      = &removingSink{}
+	)
+
+	AddSink(rs)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		Ctx(ctx).Info("first")
+		Ctx(ctx).Info("second")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sink deadlocked")
+	}
+
+	assert.Equal(t, 1, rs.writes)
+}
+
+func TestAddWriterSink_unmarshalableFloats(t *testing.T) {
+	defer ResetSinks()
+
+	var (
+		buf     bytes.Buffer
+		core, _ = observer.New(zapcore.DebugLevel)
+		ctx     = PlantLogger(context.Background(), zap.New(core).Sugar())
+	)
+
+	AddWriterSink(&buf)
+
+	Ctx(ctx).
+		With("nan", math.NaN(), "inf", math.Inf(1), "f32", float32(1.5)).
+		Info("floats")
+
+	var entry struct {
+		Fields map[string]any `json:"fields"`
+	}
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "NaN", entry.Fields["nan"])
+	assert.Equal(t, "+Inf", entry.Fields["inf"])
+	assert.Equal(t, 1.5, entry.Fields["f32"])
+}
@@ -2,6 +2,11 @@ package cluerr
 
 import (
 	"context"
+	"runtime"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
 
 	"github.com/alcionai/clues/internal/node"
 	"github.com/alcionai/clues/internal/stringify"
@@ -112,6 +117,21 @@ func Stack(errs ...error) *Err {
 	return makeStack(1, errs...)
 }
 
+// Combine joins multiple independent errors into a single error.  It
+// behaves exactly like Stack, but clarifies the intent to aggregate a
+// set of peer errors (ex: the failures from a batch of work) rather than
+// to layer errors on top of one another.
+//
+// The labels, values, and comments of every combined error are preserved
+// and aggregated in the result.  In case of value collision, the same
+// priority rules as Stack apply.
+//
+// Nil errors are filtered out.  If all errors are nil, Combine returns a
+// nil error.
+func Combine(errs ...error) error {
+	return makeStack(1, errs...).OrNil()
+}
+
 // StackWC composes a stack of one or more errors.  The first message in the
 // parameters is considered the "most recent".  Ex: a construction like
 // clues.StackWC(errFoo, io.EOF, errSmarf), the resulting Error message would
@@ -194,6 +214,28 @@ func StackWrapWC(
 	return err.WithClues(ctx)
 }
 
+// Chain appends the next error onto the Err's stack, fluently.  The result
+// is a new *Err which stacks the Err and next, in that order, so that
+// errors.Is, errors.As, and the aggregation of labels and values traverse
+// both.  Unlike Wrap, Chain adds no message.  Ex:
+//
+//	err := cluerr.New("foo").Chain(io.EOF).Chain(errSmarf)
+//	err.Error() == "foo: EOF: smarf"
+//
+// If next is nil, the Err is returned unchanged.  If the Err is nil, the
+// result stacks only next.
+func (err *Err) Chain(next error) *Err {
+	if isNilErrIface(next) {
+		return err
+	}
+
+	if isNilErrIface(err) {
+		return makeStack(1, next)
+	}
+
+	return toStack(err, []error{next}, 1)
+}
+
 // OrNil is a workaround for golang's infamous "an interface
 // holding a nil value is not nil" gotcha.  You should use it
 // to ensure the error value to produce is properly nil whenever
@@ -245,6 +287,168 @@ func (err *Err) WithMap(m map[string]any) *Err {
 	return err
 }
 
+// Overlay copies the values and labels from the src error onto the Err,
+// without adding src to the error chain.  This carries the clues forward
+// when translating an error from one layer into a new error in another.
+// Since src is not part of the chain, errors.Is and errors.As will not
+// match src or any of its wrapped errors.
+//
+// Values already present in the Err take priority over values from src.
+func (err *Err) Overlay(src error) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	if isNilErrIface(src) {
+		return err
+	}
+
+	var (
+		current = err.values()
+		values  = map[string]any{}
+	)
+
+	for k, v := range cluesIn(src) {
+		if _, ok := current[k]; !ok {
+			values[k] = v
+		}
+	}
+
+	if len(values) > 0 {
+		err.data = err.data.AddValues(values)
+	}
+
+	return err.Label(maps.Keys(Labels(src))...)
+}
+
+// WithMapPrefixed copies the map to the Err's data map, prepending
+// prefix + "." to each key.  This groups related values under a
+// namespace (ex: "db.host", "db.port").  An empty prefix behaves the
+// same as WithMap.
+func (err *Err) WithMapPrefixed(prefix string, m map[string]any) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	if len(prefix) == 0 {
+		return err.WithMap(m)
+	}
+
+	prefixed := make(map[string]any, len(m))
+
+	for k, v := range m {
+		prefixed[prefix+"."+k] = v
+	}
+
+	return err.WithMap(prefixed)
+}
+
+// WithDuration adds the duration to the Err's data map under the
+// key.  The duration is normalized to a float64 count of milliseconds
+// (ex: 1500 * time.Microsecond becomes 1.5), so that durations from
+// different errors aggregate cleanly in metrics and log queries.
+func (err *Err) WithDuration(key string, d time.Duration) *Err {
+	return err.withValue(key, float64(d)/float64(time.Millisecond))
+}
+
+// WithContextDeadline records the time remaining until the ctx deadline
+// in the Err's data map under the "deadline_remaining_ms" key, as a
+// float64 count of milliseconds (see WithDuration).  This reveals how
+// close to timing out a failure occurred.  A negative value means the
+// deadline had already passed.  No-op if the ctx has no deadline.
+func (err *Err) WithContextDeadline(ctx context.Context) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	if ctx == nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return err
+	}
+
+	return err.WithDuration("deadline_remaining_ms", time.Until(deadline))
+}
+
+// SinceCtx records the time elapsed since the start time held in the ctx
+// clues under the startKey (such as one added by clues.StartTimer).  The
+// elapsed time is added to the Err's data map under the key
+// "<startKey>_elapsed_ms", as a float64 count of milliseconds (see
+// WithDuration).  No-op if the ctx holds no start time under the key.
+func (err *Err) SinceCtx(ctx context.Context, startKey string) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	if ctx == nil {
+		return err
+	}
+
+	start, ok := toTime(node.FromCtx(ctx).RawMap()[startKey])
+	if !ok {
+		return err
+	}
+
+	return err.WithDuration(startKey+"_elapsed_ms", time.Since(start))
+}
+
+// toTime extracts a time from a clues value.  Values added through the
+// variadic normalization are stored as the time's string, and get parsed
+// back out.
+func toTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case string:
+		parsed, err := time.Parse(stringify.TimeFormat(), t)
+		return parsed, err == nil
+	}
+
+	return time.Time{}, false
+}
+
+// WithInt adds the int value to the Err's data map under the key.
+// Unlike With, the value skips the variadic normalization, and retains
+// its type when read back out of the error.
+func (err *Err) WithInt(key string, v int) *Err {
+	return err.withValue(key, v)
+}
+
+// WithString adds the string value to the Err's data map under the key.
+// Unlike With, the value skips the variadic normalization, and retains
+// its type when read back out of the error.
+func (err *Err) WithString(key string, v string) *Err {
+	return err.withValue(key, v)
+}
+
+// WithBool adds the bool value to the Err's data map under the key.
+// Unlike With, the value skips the variadic normalization, and retains
+// its type when read back out of the error.
+func (err *Err) WithBool(key string, v bool) *Err {
+	return err.withValue(key, v)
+}
+
+// WithFloat64 adds the float64 value to the Err's data map under the key.
+// Unlike With, the value skips the variadic normalization, and retains
+// its type when read back out of the error.
+func (err *Err) WithFloat64(key string, v float64) *Err {
+	return err.withValue(key, v)
+}
+
+// withValue adds a single key:value pair to the Err's data map.
+func (err *Err) withValue(key string, v any) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	err.data = err.data.AddValues(map[string]any{key: v})
+
+	return err
+}
+
 // ------------------------------------------------------------
 // stacktrace
 // ------------------------------------------------------------
@@ -308,10 +512,25 @@ func (err *Err) SkipCaller(depth int) *Err {
 
 	_, _, err.file = node.GetDirAndFile(depth + 1)
 	err.caller = node.GetCaller(depth + 1)
+	err.callerSkip = depth
 
 	return err
 }
 
+// WithCallerTag records the name of the func that called WithCallerTag
+// in the Err's data map under the "caller" key.  This attributes the
+// error to its originating func without reading the full trace.
+//
+// Any depth previously provided to SkipCaller is respected, so that
+// helper funcs report the true caller.
+func (err *Err) WithCallerTag() *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	return err.withValue("caller", node.GetCaller(err.callerSkip+1))
+}
+
 // NoTrace prevents the error from appearing in the trace stack.
 // This is particularly useful for global sentinels that get stacked
 // or wrapped into other error cases.
@@ -326,6 +545,55 @@ func (err *Err) NoTrace() *Err {
 	return err
 }
 
+// stackTracer is implemented by errors from github.com/pkg/errors.
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+// WithStackFrom imports the stack trace of the src error into this error,
+// so that %+v formatting shows where src originated.  This is useful when
+// translating a third-party error into a clues error without wrapping it.
+//
+// The src error, or any error it wraps, must implement StackTrace(), as
+// produced by github.com/pkg/errors.  The innermost (ie: original) trace
+// in the src tree is used.  If no trace is found, no ops.
+func (err *Err) WithStackFrom(src error) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	var st errors.StackTrace
+
+	for e := src; e != nil; e = unwrap(e) {
+		if tracer, ok := e.(stackTracer); ok {
+			st = tracer.StackTrace()
+		}
+	}
+
+	if len(st) == 0 {
+		return err
+	}
+
+	frames := make([]node.Frame, 0, len(st))
+
+	for _, f := range st {
+		// pkg/errors frames hold the program counter + 1.
+		pc := uintptr(f) - 1
+		frame := node.Frame{}
+
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			frame.Func = fn.Name()
+			frame.File, frame.Line = fn.FileLine(pc)
+		}
+
+		frames = append(frames, frame)
+	}
+
+	err.importedStack = frames
+
+	return err
+}
+
 // ---------------------------------------------------------------------------
 // helpers
 // ---------------------------------------------------------------------------
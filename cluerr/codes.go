@@ -0,0 +1,128 @@
+package cluerr
+
+// ------------------------------------------------------------
+// codes
+// ------------------------------------------------------------
+
+// WithCode sets a stable, machine-readable code on the error, such as
+// "user_not_found".  Codes are kept separate from labels and values, and
+// each error holds at most one code.  Calling WithCode again replaces the
+// prior code.
+func (err *Err) WithCode(code string) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	err.code = code
+
+	return err
+}
+
+// Code retrieves the code of the error.  Returns an empty string
+// if no code was set.
+func (err *Err) Code() string {
+	return Code(err)
+}
+
+// Code retrieves the code of the error.  If multiple errors in the tree
+// have a code, the outermost code wins.  Returns an empty string if no
+// code was set.
+func Code(err error) string {
+	ce := outermost(err, func(ce *Err) bool { return len(ce.code) > 0 })
+	if ce == nil {
+		return ""
+	}
+
+	return ce.code
+}
+
+// codeError is an errors.Is target which matches errors by code.
+type codeError struct {
+	code string
+}
+
+func (ce codeError) Error() string {
+	return "error code: " + ce.code
+}
+
+// CodeError produces a target for errors.Is which matches any error in
+// the tree that carries the code.  This allows for idiomatic branching
+// on codes:
+//
+//	if errors.Is(err, cluerr.CodeError("user_not_found")) {
+//		...
+//	}
+func CodeError(code string) error {
+	return codeError{code: code}
+}
+
+// TallyCodes counts the leaf errors in the tree by code.  This is useful
+// for summarizing a combined error, such as one produced by stacking the
+// errors from a batch of operations.
+//
+// A leaf is an *Err which doesn't contain any other *Err, or an error of
+// any other type which neither wraps nor stacks another error and isn't
+// contained by a leaf *Err.  Each leaf is counted under its own code,
+// regardless of the codes of the errors which wrap or stack it.  Leaves
+// without a code are counted under "".
+func TallyCodes(err error) map[string]int {
+	tally := map[string]int{}
+
+	if isNilErrIface(err) {
+		return tally
+	}
+
+	_, plain := tallyCodes(err, tally)
+	if plain > 0 {
+		tally[""] += plain
+	}
+
+	return tally
+}
+
+// tallyCodes is the recursive helper for TallyCodes.  Leaf-ness is
+// decided in the same pass, so the tree is only walked once.  Returns
+// true if the tree contains any *Err, along with the number of leaves
+// of other types that haven't been counted yet, since those are only
+// counted if no leaf *Err contains them.
+func tallyCodes(err error, tally map[string]int) (bool, int) {
+	var (
+		hasErr bool
+		plain  int
+		leaf   = true
+	)
+
+	ce, ok := err.(*Err)
+
+	if ok {
+		for _, se := range ce.stack {
+			if !isNilErrIface(se) {
+				leaf = false
+				h, p := tallyCodes(se, tally)
+				hasErr, plain = hasErr || h, plain+p
+			}
+		}
+	}
+
+	if unwrapped := unwrap(err); !isNilErrIface(unwrapped) {
+		leaf = false
+		h, p := tallyCodes(unwrapped, tally)
+		hasErr, plain = hasErr || h, plain+p
+	}
+
+	switch {
+	case ok && !hasErr:
+		tally[ce.code]++
+		return true, 0
+	case ok:
+		if plain > 0 {
+			tally[""] += plain
+		}
+
+		return true, 0
+	case leaf:
+		return hasErr, 1
+	}
+
+	return hasErr, plain
+}
@@ -0,0 +1,41 @@
+package cluerr
+
+// ------------------------------------------------------------
+// counts
+// ------------------------------------------------------------
+
+// WithCount records the number of times the error occurred, such as when
+// deduplicating repeated, identical errors, so that a summarizer can
+// report that an operation "failed 37 times".  Calling WithCount again
+// replaces the prior count.  Counts less than one are ignored.
+func (err *Err) WithCount(n int) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	if n < 1 {
+		return err
+	}
+
+	err.count = n
+
+	return err
+}
+
+// Count retrieves the number of times the error occurred.  Returns false
+// if no count was set.
+func (err *Err) Count() (int, bool) {
+	return Count(err)
+}
+
+// Count retrieves the number of times the error occurred.  If multiple
+// errors in the tree have a count, the outermost count wins.  Returns
+// false if no count was set.
+func Count(err error) (int, bool) {
+	ce := outermost(err, func(ce *Err) bool { return ce.count > 0 })
+	if ce == nil {
+		return 0, false
+	}
+
+	return ce.count, true
+}
@@ -0,0 +1,143 @@
+package cluerr
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alcionai/clues/internal/stringify"
+	"golang.org/x/exp/maps"
+)
+
+// ------------------------------------------------------------
+// reports
+// ------------------------------------------------------------
+
+// Describe produces a human-readable report of the error, for use in
+// CLI tools and panic handlers.  The report contains the following
+// sections, in order:
+//
+//	error:    the full error message
+//	messages: each message in the error chain, outermost first
+//	labels:   the sorted labels
+//	values:   the values, sorted by key, with concealed values
+//	          remaining concealed
+//	comments: the comment history
+//	trace:    the caller and file of each error in the chain,
+//	          outermost first
+//
+// Sections without any content are omitted.
+func Describe(err error) string {
+	if isNilErrIface(err) {
+		return "<nil>"
+	}
+
+	var (
+		sb   strings.Builder
+		core = ToCore(err)
+	)
+
+	section := func(name string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+
+		sb.WriteString(name + ":")
+
+		for _, line := range lines {
+			sb.WriteString("\n\t" + strings.ReplaceAll(line, "\n", "\n\t"))
+		}
+	}
+
+	section("error", []string{err.Error()})
+	section("messages", describeMessages(err))
+
+	labels := maps.Keys(core.Labels)
+	slices.Sort(labels)
+	section("labels", labels)
+
+	section("values", describeValues(core.Values))
+
+	if len(core.Comments) > 0 {
+		section("comments", []string{core.Comments.String()})
+	}
+
+	section("trace", describeTrace(err))
+
+	return sb.String()
+}
+
+// describeMessages lists the non-empty messages in the error chain.
+func describeMessages(err error) []string {
+	msgs := []string{}
+
+	for _, msg := range MessageChain(err) {
+		if len(msg) > 0 {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	// a single message just repeats the error section.
+	if len(msgs) < 2 {
+		return nil
+	}
+
+	return msgs
+}
+
+// describeValues lists each value as an aligned `key = value` row.
+func describeValues(vs map[string]any) []string {
+	var (
+		keys  = maps.Keys(vs)
+		width int
+		rows  = make([]string, 0, len(vs))
+	)
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		width = max(width, len(k))
+	}
+
+	for _, k := range keys {
+		rows = append(rows, fmt.Sprintf("%-*s = %s", width, k, stringify.Marshal(vs[k], true)))
+	}
+
+	return rows
+}
+
+// describeTrace lists the caller and file of each *Err in the chain,
+// from the outermost error to the oldest.
+func describeTrace(err error) []string {
+	var (
+		ancs  = ancestors(err)
+		trace = []string{}
+	)
+
+	for i := len(ancs) - 1; i >= 0; i-- {
+		ce, ok := ancs[i].(*Err)
+		if !ok {
+			continue
+		}
+
+		parts := []string{}
+
+		if len(ce.caller) > 0 {
+			parts = append(parts, ce.caller)
+		}
+
+		if len(ce.file) > 0 {
+			parts = append(parts, ce.file)
+		}
+
+		if len(parts) > 0 {
+			trace = append(trace, strings.Join(parts, " - "))
+		}
+	}
+
+	return trace
+}
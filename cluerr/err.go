@@ -5,10 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"path"
 	"reflect"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/alcionai/clues/internal/node"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/exp/maps"
 )
 
@@ -30,6 +37,8 @@ type Err struct {
 	file string
 	// the name of the func where the error (or wrapper) was generated.
 	caller string
+	// callerSkip is the depth most recently provided to SkipCaller.
+	callerSkip int
 
 	// msg is the message for this error.
 	msg string
@@ -39,6 +48,38 @@ type Err struct {
 	// categorization without applying an error type.
 	labels map[string]struct{}
 
+	// labelsStripped is a tombstone which hides the labels of all
+	// wrapped and stacked errors.  Only labels added directly to
+	// this error after it was stripped are retained.
+	labelsStripped bool
+
+	// code is a stable, machine-readable identifier for the error.
+	code string
+
+	// fingerprint is the set of parts used by error-tracking systems
+	// to group the error.
+	fingerprint []string
+
+	// count is the number of times the error occurred.  Zero means
+	// no count was set.
+	count int
+
+	// httpStatus is the http status code associated with the error.
+	// Zero means no status was set.
+	httpStatus int
+
+	// retryAfter is the suggested delay before retrying the operation
+	// which produced the error.  Nil means no delay was suggested.
+	retryAfter *time.Duration
+
+	// otelStatus is the status to report on a span when the error
+	// gets recorded to it.  Nil means the default status is used.
+	otelStatus *otelStatus
+
+	// importedStack holds the stack frames imported from a
+	// third-party error by WithStackFrom.
+	importedStack []node.Frame
+
 	// data is the record of contextual data produced,
 	// presumably, at the time the error is created or wrapped.
 	data *node.Node
@@ -67,6 +108,25 @@ func ancestors(err error) []error {
 	return stackAncestorsOntoSelf(err)
 }
 
+// outermost returns the outermost *Err in the tree for which fn returns
+// true, or nil if there is none.
+func outermost(err error, fn func(*Err) bool) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	ancs := ancestors(err)
+
+	for i := len(ancs) - 1; i >= 0; i-- {
+		ce, ok := ancs[i].(*Err)
+		if ok && fn(ce) {
+			return ce
+		}
+	}
+
+	return nil
+}
+
 // a recursive function, purely for building out ancestorStack.
 func stackAncestorsOntoSelf(err error) []error {
 	if err == nil {
@@ -94,6 +154,80 @@ func stackAncestorsOntoSelf(err error) []error {
 	return errs
 }
 
+// ErrNode is a single error within an error tree.
+type ErrNode struct {
+	// Err is the error at this position in the tree.
+	Err error
+	// Clues holds the same error as Err if it is an *Err.
+	// Otherwise it is nil.
+	Clues *Err
+}
+
+// All produces an iterator over every error in the tree, for use in
+// range-over-func loops:
+//
+//	for en := range err.All() {
+//		...
+//	}
+//
+// Errors are visited in the same order as the ancestor lineage: the
+// oldest ancestor comes first and the error itself comes last, with
+// stacked errors visited before wrapped errors.
+func (err *Err) All() iter.Seq[ErrNode] {
+	return func(yield func(ErrNode) bool) {
+		if isNilErrIface(err) {
+			return
+		}
+
+		for _, ancestor := range ancestors(err) {
+			en := ErrNode{Err: ancestor}
+			en.Clues, _ = ancestor.(*Err)
+
+			if !yield(en) {
+				return
+			}
+		}
+	}
+}
+
+// WalkDepth visits every error in the tree in pre-order: the error itself
+// comes first at depth 0, followed by each of its stacked errors, and then
+// the error it wraps.  The depth of each node is its distance from the
+// root, which allows tooling to render an indented view of the tree.
+// Walking stops when fn returns false.
+func WalkDepth(err error, fn func(depth int, node ErrNode) bool) {
+	if isNilErrIface(err) {
+		return
+	}
+
+	walkDepth(err, 0, fn)
+}
+
+// walkDepth is the recursive helper for WalkDepth.  Returns false if
+// the walk should stop.
+func walkDepth(err error, depth int, fn func(int, ErrNode) bool) bool {
+	en := ErrNode{Err: err}
+	en.Clues, _ = err.(*Err)
+
+	if !fn(depth, en) {
+		return false
+	}
+
+	if en.Clues != nil {
+		for _, se := range en.Clues.stack {
+			if !isNilErrIface(se) && !walkDepth(se, depth+1, fn) {
+				return false
+			}
+		}
+	}
+
+	if unwrapped := unwrap(err); unwrapped != nil {
+		return walkDepth(unwrapped, depth+1, fn)
+	}
+
+	return true
+}
+
 // ------------------------------------------------------------
 // eror interface compliance and stringers
 // ------------------------------------------------------------
@@ -123,6 +257,85 @@ func (err *Err) Error() string {
 	return strings.Join(msg, ": ")
 }
 
+// MessageChain returns each individual message in the error tree, ordered
+// from the most recent (outermost) error to the oldest.  Unlike Error(),
+// the messages are kept as separate segments instead of being joined.
+//
+// Non-clues errors that wrap other errors only contribute the portion of
+// their message that precedes the wrapped error's message.
+func MessageChain(err error) []string {
+	if isNilErrIface(err) {
+		return []string{}
+	}
+
+	ancs := ancestors(err)
+	chain := make([]string, 0, len(ancs))
+
+	for i := len(ancs) - 1; i >= 0; i-- {
+		var (
+			ancestor = ancs[i]
+			msg      string
+		)
+
+		if ce, ok := ancestor.(*Err); ok {
+			msg = ce.msg
+		} else {
+			msg = ancestor.Error()
+
+			if inner := unwrap(ancestor); !isNilErrIface(inner) {
+				msg = strings.TrimSuffix(msg, inner.Error())
+				msg = strings.TrimSuffix(msg, ": ")
+			}
+		}
+
+		if len(msg) > 0 {
+			chain = append(chain, msg)
+		}
+	}
+
+	return chain
+}
+
+// MessageChain returns each individual message in the error tree, ordered
+// from the most recent (outermost) error to the oldest.  Unlike Error(),
+// the messages are kept as separate segments instead of being joined.
+func (err *Err) MessageChain() []string {
+	return MessageChain(err)
+}
+
+// MessageContains returns true if any individual message in the error's
+// MessageChain contains the substring.  This is safer than checking
+// strings.Contains(err.Error(), substr), which can spuriously match
+// across the delimiter between two joined messages.
+func MessageContains(err error, substr string) bool {
+	for _, msg := range MessageChain(err) {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MessageContains returns true if any individual message in the error's
+// MessageChain contains the substring.
+func (err *Err) MessageContains(substr string) bool {
+	return MessageContains(err, substr)
+}
+
+// MatchMessageAndLabels returns true if both errors have the same
+// MessageChain and the same set of labels.  Values, comments, and other
+// metadata are ignored, which makes this handy for test assertions
+// against errors whose values include volatile data, like timestamps.
+func MatchMessageAndLabels(a, b error) bool {
+	if isNilErrIface(a) || isNilErrIface(b) {
+		return isNilErrIface(a) == isNilErrIface(b)
+	}
+
+	return slices.Equal(MessageChain(a), MessageChain(b)) &&
+		maps.Equal(Labels(a), Labels(b))
+}
+
 // format is the fallback formatting of an error
 func format(err error, s fmt.State, verb rune) {
 	if isNilErrIface(err) {
@@ -195,6 +408,13 @@ func formatPlusV(err *Err, s fmt.State, verb rune) {
 	}
 
 	write(s, verb, "\n\t%s", strings.Join(parts, " - "))
+
+	for _, frame := range err.importedStack {
+		dir, file := path.Split(frame.File)
+		fileLine := fmt.Sprintf("%s:%d", path.Join(path.Base(dir), file), frame.Line)
+
+		write(s, verb, "\n\t%s", frame.Func+" - "+fileLine)
+	}
 }
 
 // Format ensures stack traces are printed appropariately.
@@ -251,12 +471,17 @@ func write(s fmt.State, verb rune, msgs ...string) {
 // Is overrides the standard Is check for Err.e, allowing us to check
 // the conditional for both Err.e and Err.stack.  This allows clues to
 // Stack() multiple error pointers without failing the otherwise linear
-// errors.Is check.
+// errors.Is check.  Targets produced by CodeError match errors which
+// carry the same code.
 func (err *Err) Is(target error) bool {
 	if isNilErrIface(err) {
 		return false
 	}
 
+	if ce, ok := target.(codeError); ok && len(ce.code) > 0 && err.code == ce.code {
+		return true
+	}
+
 	if errors.Is(err.e, target) {
 		return true
 	}
@@ -309,6 +534,21 @@ func (err *Err) Unwrap() error {
 	return err.e
 }
 
+// Cause provides compatibility for github.com/pkg/errors chains.
+// Cause returns the base error, allowing errors.Cause(err) to
+// traverse through clues errors:
+//
+//	type causer interface {
+//	       Cause() error
+//	}
+func (err *Err) Cause() error {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	return err.e
+}
+
 // unwrap attempts to unwrap any generic error.
 func unwrap(err error) error {
 	if isNilErrIface(err) {
@@ -341,19 +581,140 @@ func unwrap(err error) error {
 // clues.Stack(err).WithMap(clues.Values(ctx)).
 //
 // If the context contains a clues LabelCounter, that counter is
-// passed to the error.  WithClues must always be called first in
-// order to count labels.
+// passed to the error.  A context without a counter leaves the
+// error's current counter in place.  WithClues must always be called
+// first in order to count labels.
+//
+// If SetIncludeBaggage is enabled, the members of the ctx's otel
+// baggage are also added, with each key prefixed by BaggagePrefix.
+//
+// If the ctx holds an active otel span, its trace ID is recorded on the
+// error.  See TraceID.
 func (err *Err) WithClues(ctx context.Context) *Err {
 	if isNilErrIface(err) {
 		return nil
 	}
 
 	dn := node.FromCtx(ctx)
-	e := err.WithMap(dn.Map())
+	e := err.WithMap(dn.RawMap())
+
+	if dn.LabelCounter != nil {
+		e.data.LabelCounter = dn.LabelCounter
+	}
+
+	if includeBaggage.Load() {
+		e = e.WithMap(baggageValues(ctx))
+	}
+
+	if tid := traceIDOf(ctx, dn); len(tid) > 0 {
+		e = e.withValue(node.TraceIDKey, tid)
+	}
 
 	return e
 }
 
+// traceIDOf retrieves the ID of the trace active in the ctx, if any.
+func traceIDOf(ctx context.Context, dn *node.Node) string {
+	var sc trace.SpanContext
+
+	if ctx != nil {
+		sc = trace.SpanContextFromContext(ctx)
+	}
+
+	if !sc.IsValid() && dn.Span != nil {
+		sc = dn.Span.SpanContext()
+	}
+
+	if !sc.IsValid() {
+		return ""
+	}
+
+	return sc.TraceID().String()
+}
+
+// TraceID retrieves the ID of the otel trace which was active in the ctx
+// when the error was built with WithClues (or with funcs that call it,
+// such as NewWC and WrapWC).  Returns an empty string if no trace was
+// active.
+func (err *Err) TraceID() string {
+	return TraceID(err)
+}
+
+// TraceID retrieves the ID of the otel trace which was active in the ctx
+// when the error was built with WithClues (or with funcs that call it,
+// such as NewWC and WrapWC).  If multiple errors in the tree captured a
+// trace, the deepest capture wins, following the same priority as the
+// error's values.  Returns an empty string if no trace was active.
+func TraceID(err error) string {
+	tid, _ := cluesIn(err)[node.TraceIDKey].(string)
+	return tid
+}
+
+// BaggagePrefix is prepended to the keys of baggage members when they
+// get added to an error's values.
+const BaggagePrefix = "baggage."
+
+// includeBaggage toggles whether WithClues adds the baggage members.
+var includeBaggage atomic.Bool
+
+// SetIncludeBaggage toggles whether errors built from a ctx (ex: NewWC,
+// WrapWC, or err.WithClues(ctx)) also carry the members of the ctx's
+// otel baggage.  Baggage often holds the canonical request identifiers
+// shared across services, so this ensures errors carry the same ids as
+// logs and traces.  Each member is added to the error's values under its
+// key prefixed with BaggagePrefix (ex: "baggage.tenant_id").  Defaults
+// to false.
+func SetIncludeBaggage(include bool) {
+	includeBaggage.Store(include)
+}
+
+// baggageValues produces the members of the ctx's baggage, keyed by
+// the BaggagePrefix'd member key.
+func baggageValues(ctx context.Context) map[string]any {
+	if ctx == nil {
+		return nil
+	}
+
+	members := baggage.FromContext(ctx).Members()
+	values := make(map[string]any, len(members))
+
+	for _, m := range members {
+		values[BaggagePrefix+m.Key()] = m.Value()
+	}
+
+	return values
+}
+
+// RequestIDKey is the clues key holding the request ID.
+const RequestIDKey = "request_id"
+
+// WithRequestIDFromCtx copies only the request ID (the value stored under
+// RequestIDKey) from the clues in the ctx onto the error.  This is a
+// targeted alternative to WithClues for cases where adding every value in
+// the ctx is undesirable.  If the ctx has no request ID, no change is made.
+func (err *Err) WithRequestIDFromCtx(ctx context.Context) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	rid, ok := node.FromCtx(ctx).RawMap()[RequestIDKey]
+	if !ok {
+		return err
+	}
+
+	return err.WithMap(map[string]any{RequestIDKey: rid})
+}
+
+// CluesProvider can be implemented by third-party error types to expose
+// their structured data to clues.  Funcs that aggregate the data in an
+// error tree (ex: CluesIn, Labels, ToCore) include the values and labels
+// of any CluesProvider in the tree, alongside the data of clues' own
+// errors.
+type CluesProvider interface {
+	Values() map[string]any
+	Labels() map[string]struct{}
+}
+
 // CluesIn returns the structured data in the error.
 // Each error in the stack is unwrapped and all maps are
 // unioned. In case of collision, lower level error data
@@ -375,6 +736,17 @@ func cluesIn(err error) map[string]any {
 		return e.values()
 	}
 
+	if cp, ok := err.(CluesProvider); ok {
+		vals := maps.Clone(cp.Values())
+		if vals == nil {
+			vals = map[string]any{}
+		}
+
+		maps.Copy(vals, cluesIn(unwrap(err)))
+
+		return vals
+	}
+
 	return cluesIn(unwrap(err))
 }
 
@@ -390,13 +762,27 @@ func (err *Err) Values() *node.Node {
 	return &node.Node{Values: err.values()}
 }
 
+// UserValues returns a copy of the contextual data in the error, like
+// Values, but without the keys reserved by clues (such as the
+// clues_trace and agents).  Only the values added by the end user
+// remain.
+func (err *Err) UserValues() map[string]any {
+	vals := err.Values().Map()
+
+	for _, k := range node.ReservedKeys() {
+		delete(vals, k)
+	}
+
+	return vals
+}
+
 func (err *Err) values() map[string]any {
 	if isNilErrIface(err) {
 		return map[string]any{}
 	}
 
 	vals := map[string]any{}
-	maps.Copy(vals, err.data.Map())
+	maps.Copy(vals, err.data.RawMap())
 	maps.Copy(vals, cluesIn(err.e))
 
 	for _, se := range err.stack {
@@ -406,6 +792,82 @@ func (err *Err) values() map[string]any {
 	return vals
 }
 
+// ValuesOption configures how ValuesWith resolves collisions between
+// values with the same key.
+type ValuesOption func(*valuesConfig)
+
+type valuesConfig struct {
+	outermostWins bool
+}
+
+// RootWins gives priority to the values of the deepest (root) errors in
+// the tree.  This is the default precedence, and matches Values().
+func RootWins() ValuesOption {
+	return func(vc *valuesConfig) {
+		vc.outermostWins = false
+	}
+}
+
+// OutermostWins inverts the default precedence, giving priority to the
+// values of the outermost errors in the tree.  Within a stack, the first
+// stacked error wins.
+func OutermostWins() ValuesOption {
+	return func(vc *valuesConfig) {
+		vc.outermostWins = true
+	}
+}
+
+// ValuesWith returns a copy of all of the contextual data in the error,
+// the same as Values(), except that collisions are resolved according to
+// the provided options.  Later options override earlier ones.
+func (err *Err) ValuesWith(opts ...ValuesOption) *node.Node {
+	if isNilErrIface(err) {
+		return &node.Node{}
+	}
+
+	vc := valuesConfig{}
+
+	for _, opt := range opts {
+		opt(&vc)
+	}
+
+	if !vc.outermostWins {
+		return err.Values()
+	}
+
+	return &node.Node{Values: outermostValues(err)}
+}
+
+// outermostValues unions the values in the error tree, giving priority
+// to the outermost errors.
+func outermostValues(err error) map[string]any {
+	if isNilErrIface(err) {
+		return map[string]any{}
+	}
+
+	ce, ok := err.(*Err)
+	if !ok {
+		vals := outermostValues(unwrap(err))
+
+		if cp, ok := err.(CluesProvider); ok {
+			maps.Copy(vals, cp.Values())
+		}
+
+		return vals
+	}
+
+	vals := map[string]any{}
+
+	for i := len(ce.stack) - 1; i >= 0; i-- {
+		maps.Copy(vals, outermostValues(ce.stack[i]))
+	}
+
+	maps.Copy(vals, outermostValues(ce.e))
+	maps.Copy(vals, ce.data.RawMap())
+
+	return vals
+}
+
 // ------------------------------------------------------------
 // helpers
 // ------------------------------------------------------------
@@ -417,7 +879,20 @@ func isNilErrIface(err error) bool {
 		return true
 	}
 
-	val := reflect.ValueOf(err)
+	// fast path: the common concrete types can be checked without
+	// reflection, since this gets called on nearly every Err func.
+	switch e := err.(type) {
+	case *Err:
+		return e == nil
+	case codeError:
+		return false
+	}
+
+	// only pointer kinds can hold a nil value.  The dynamic type of an
+	// interface is never itself an interface.
+	if reflect.TypeOf(err).Kind() != reflect.Pointer {
+		return false
+	}
 
-	return ((val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface) && val.IsNil())
+	return reflect.ValueOf(err).IsNil()
 }
@@ -2,6 +2,7 @@ package cluerr_test
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"testing"
 
@@ -350,3 +351,52 @@ func BenchmarkInErr_rand(b *testing.B) {
 	}
 	_ = m
 }
+
+func BenchmarkStack_err(b *testing.B) {
+	err := cluerr.New("err")
+	for i := 0; i < b.N; i++ {
+		_ = cluerr.Stack(err)
+	}
+}
+
+func BenchmarkStack_stdErr(b *testing.B) {
+	err := errors.New("err")
+	for i := 0; i < b.N; i++ {
+		_ = cluerr.Stack(err)
+	}
+}
+
+func BenchmarkStack_nilErr(b *testing.B) {
+	var err *cluerr.Err
+	for i := 0; i < b.N; i++ {
+		_ = cluerr.Stack(err)
+	}
+}
+
+func BenchmarkGroupByLabel_deep(b *testing.B) {
+	err := cluerr.New("err").Label("leaf")
+	for i := 0; i < 100; i++ {
+		err = cluerr.Stack(err, cluerr.New("sibling")).Label("wrapper")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = cluerr.GroupByLabel(err)
+	}
+}
+
+func BenchmarkTallyCodes_deep(b *testing.B) {
+	err := cluerr.New("err").WithCode("leaf")
+	for i := 0; i < 100; i++ {
+		err = cluerr.Stack(err, errors.New("sibling")).WithCode("wrapper")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = cluerr.TallyCodes(err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/alcionai/clues/cluerr"
 )
@@ -1590,6 +1592,53 @@ func TestSkipCaller(t *testing.T) {
 	}
 }
 
+func thirdPartyOrigin() error {
+	return errors.New("third party")
+}
+
+func TestWithStackFrom(t *testing.T) {
+	table := []struct {
+		name   string
+		src    error
+		expect *regexp.Regexp
+	}{
+		{
+			name:   "pkg errors origin",
+			src:    thirdPartyOrigin(),
+			expect: regexp.MustCompile(`\n\t.*cluerr_test.thirdPartyOrigin - cluerr/err_fmt_test.go:\d+\n`),
+		},
+		{
+			name:   "pkg errors wrapped",
+			src:    errors.Wrap(thirdPartyOrigin(), "wrap"),
+			expect: regexp.MustCompile(`\n\t.*cluerr_test.thirdPartyOrigin - cluerr/err_fmt_test.go:\d+\n`),
+		},
+		{
+			name:   "fmt wrapped",
+			src:    fmt.Errorf("fmt: %w", thirdPartyOrigin()),
+			expect: regexp.MustCompile(`\n\t.*cluerr_test.thirdPartyOrigin - cluerr/err_fmt_test.go:\d+\n`),
+		},
+		{
+			name:   "no stack trace",
+			src:    stderr.New("std"),
+			expect: regexp.MustCompile(`^translated\n\tTestWithStackFrom - cluerr/err_fmt_test.go:\d+$`),
+		},
+		{
+			name:   "nil",
+			src:    nil,
+			expect: regexp.MustCompile(`^translated\n\tTestWithStackFrom - cluerr/err_fmt_test.go:\d+$`),
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			err := cluerr.New("translated").WithStackFrom(test.src)
+			checkFmt{"%+v", "", test.expect}.check(t, err)
+
+			// the message is unaffected.
+			checkFmt{"%v", "translated", regexp.MustCompile(`^translated$`)}.check(t, err)
+		})
+	}
+}
+
 func TestComment(t *testing.T) {
 	table := []struct {
 		name          string
@@ -1725,6 +1774,109 @@ func TestComment(t *testing.T) {
 	}
 }
 
+func TestJSONString(t *testing.T) {
+	err := cluerr.New("message").
+		With("key", "value", "int", 1).
+		Label("label")
+
+	assert.Equal(
+		t,
+		`{"msg":"message","labels":{"label":{}},"values":{"int":"1","key":"value"},"comments":[]}`,
+		err.JSONString(false))
+
+	expectPretty := `{
+  "msg": "message",
+  "labels": {
+    "label": {}
+  },
+  "values": {
+    "int": "1",
+    "key": "value"
+  },
+  "comments": []
+}`
+	assert.Equal(t, expectPretty, err.JSONString(true))
+
+	var nilErr *cluerr.Err
+	assert.Equal(t, "null", nilErr.JSONString(false))
+}
+
+func TestAsMap(t *testing.T) {
+	inner := cluerr.New("inner").
+		With("key", "value").
+		Label("b_label", "a_label").
+		Comment("a comment")
+	err := cluerr.Wrap(inner, "outer").
+		WithCode("a_code").
+		With("int", 1)
+
+	m := err.AsMap()
+
+	assert.Equal(t, "outer: inner", m["message"])
+	assert.Equal(t, []string{"a_label", "b_label"}, m["labels"])
+	assert.Equal(t, map[string]any{"key": "value", "int": "1"}, m["values"])
+	assert.Equal(t, []string{"a comment"}, m["comments"])
+	assert.Equal(t, "a_code", m["code"])
+
+	trace, ok := m["trace"].([]string)
+	require.True(t, ok, "trace is a []string")
+	require.Len(t, trace, 2)
+
+	for _, line := range trace {
+		assert.Regexp(t, `^TestAsMap - \S*err_fmt_test.go:\d+$`, line)
+	}
+
+	// sections without content are still present, except the code.
+	m = cluerr.New("bare").AsMap()
+	assert.Equal(t, "bare", m["message"])
+	assert.Equal(t, []string{}, m["labels"])
+	assert.Equal(t, map[string]any{}, m["values"])
+	assert.Equal(t, []string{}, m["comments"])
+	assert.NotContains(t, m, "code")
+
+	var nilErr *cluerr.Err
+	assert.Empty(t, nilErr.AsMap())
+}
+
+func TestDescribe(t *testing.T) {
+	inner := cluerr.New("inner").
+		With("key", "value", "longer_key", 1).
+		Label("b_label", "a_label").
+		Comment("a comment")
+	err := cluerr.Wrap(inner, "outer")
+
+	assert.Regexp(
+		t,
+		regexp.MustCompile(`^error:
+	outer: inner
+
+messages:
+	outer
+	inner
+
+labels:
+	a_label
+	b_label
+
+values:
+	key        = value
+	longer_key = 1
+
+comments:
+	TestDescribe - \S*err_fmt_test.go:\d+
+		a comment
+
+trace:
+	TestDescribe - \S*err_fmt_test.go:\d+
+	TestDescribe - \S*err_fmt_test.go:\d+$`),
+		cluerr.Describe(err))
+
+	// plain errors only produce the sections they can fill.
+	assert.Equal(t, "error:\n\tplain", cluerr.Describe(stderr.New("plain")))
+
+	assert.Equal(t, "<nil>", cluerr.Describe(nil))
+}
+
 func TestErrCore_String(t *testing.T) {
 	table := []struct {
 		name        string
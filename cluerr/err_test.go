@@ -3,12 +3,24 @@ package cluerr_test
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
 	"golang.org/x/exp/maps"
 
 	"github.com/alcionai/clues"
+	"github.com/alcionai/clues/cecrets"
 	"github.com/alcionai/clues/cluerr"
 	"github.com/alcionai/clues/internal/tester"
 )
@@ -220,6 +232,665 @@ func TestLabel(t *testing.T) {
 	}
 }
 
+func TestLabelAll(t *testing.T) {
+	var (
+		a       = cluerr.New("a")
+		b       = cluerr.New("b").Label("b")
+		stacked = cluerr.Stack(fmt.Errorf("%w", a), b)
+		wrapped = cluerr.Wrap(stacked, "wrapped")
+	)
+
+	err := cluerr.LabelAll(wrapped, "batch-123", "batch-123")
+	if err != wrapped {
+		t.Error("expected LabelAll to return the same error")
+	}
+
+	for name, e := range map[string]*cluerr.Err{
+		"a":       a,
+		"b":       b,
+		"stacked": stacked,
+		"wrapped": wrapped,
+	} {
+		if _, ok := e.Labels()["batch-123"]; !ok {
+			t.Errorf("expected error [%s] to have label [batch-123]", name)
+		}
+	}
+
+	if !b.HasLabel("b") {
+		t.Error("expected error [b] to retain label [b]")
+	}
+
+	if a.HasLabel("b") {
+		t.Error("expected error [a] to not receive label [b]")
+	}
+
+	if cluerr.LabelAll(nil, "batch-123") != nil {
+		t.Error("expected nil error to remain nil")
+	}
+
+	err = cluerr.LabelAll(errors.New("plain"), "batch-123")
+	if !cluerr.HasLabel(err, "batch-123") {
+		t.Error("expected non-clues error to receive label [batch-123]")
+	}
+}
+
+func TestStripLabels(t *testing.T) {
+	var (
+		a       = cluerr.New("a").Label("a")
+		b       = cluerr.New("b").Label("b")
+		stacked = cluerr.Stack(a, fmt.Errorf("%w", b)).Label("stacked")
+	)
+
+	tester.MustEquals(
+		t,
+		msa{"a": struct{}{}, "b": struct{}{}, "stacked": struct{}{}},
+		toMSA(stacked.Labels()),
+		false)
+
+	stacked.StripLabels()
+
+	tester.MustEquals(t, msa{}, toMSA(stacked.Labels()), false)
+	tester.MustEquals(t, msa{}, toMSA(cluerr.Labels(fmt.Errorf("%w", stacked))), false)
+
+	for _, l := range []string{"a", "b", "stacked"} {
+		if stacked.HasLabel(l) {
+			t.Errorf("expected stripped error to not have label [%s]", l)
+		}
+	}
+
+	// children are unaffected
+	if !a.HasLabel("a") || !b.HasLabel("b") {
+		t.Error("expected stacked children to retain their labels")
+	}
+
+	// labels added after stripping are retained, and
+	// wrappers still see them.
+	stacked.Label("new")
+
+	tester.MustEquals(t, msa{"new": struct{}{}}, toMSA(stacked.Labels()), false)
+
+	wrapped := cluerr.Wrap(stacked, "wrap").Label("wrap")
+	tester.MustEquals(t, msa{"new": struct{}{}, "wrap": struct{}{}}, toMSA(wrapped.Labels()), false)
+}
+
+func TestGroupByLabel(t *testing.T) {
+	var (
+		a        = cluerr.New("a").Label("network")
+		b        = cluerr.Wrap(errors.New("b"), "wrap b").Label("network", "retryable")
+		c        = cluerr.New("c")
+		d        = cluerr.New("d").Label("auth")
+		wrapped  = cluerr.Wrap(fmt.Errorf("%w", d), "wrap d").Label("ignored")
+		stacked  = cluerr.Stack(a, b, c, wrapped).Label("batch")
+		expected = map[string][]error{
+			"network":   {a, b},
+			"retryable": {b},
+			"":          {c},
+			"auth":      {d},
+		}
+	)
+
+	result := cluerr.GroupByLabel(stacked)
+
+	if len(result) != len(expected) {
+		t.Errorf("expected [%d] groups, got [%d]: %v", len(expected), len(result), result)
+	}
+
+	for label, errs := range expected {
+		got := result[label]
+
+		if len(got) != len(errs) {
+			t.Errorf("expected group [%s] to have [%d] errors, got [%d]: %v", label, len(errs), len(got), got)
+			continue
+		}
+
+		for _, e := range errs {
+			if !slices.Contains(got, e) {
+				t.Errorf("expected group [%s] to contain error [%v]", label, e)
+			}
+		}
+	}
+
+	if len(cluerr.GroupByLabel(nil)) > 0 {
+		t.Error("expected nil error to produce no groups")
+	}
+}
+
+func TestOutermostAccessors(t *testing.T) {
+	// each accessor sets its value from an int, so that the inner (1) and
+	// outer (2) settings are distinguishable.
+	accessors := []struct {
+		name   string
+		set    func(err *cluerr.Err, v int) *cluerr.Err
+		get    func(err error) (any, bool)
+		expect func(v int) any
+		unset  any
+	}{
+		{
+			name: "code",
+			set:  func(err *cluerr.Err, v int) *cluerr.Err { return err.WithCode(fmt.Sprint(v)) },
+			get: func(err error) (any, bool) {
+				c := cluerr.Code(err)
+				return c, len(c) > 0
+			},
+			expect: func(v int) any { return fmt.Sprint(v) },
+			unset:  "",
+		},
+		{
+			name: "fingerprint",
+			set:  func(err *cluerr.Err, v int) *cluerr.Err { return err.WithFingerprint(fmt.Sprint(v)) },
+			get: func(err error) (any, bool) {
+				fp := cluerr.Fingerprint(err)
+				return fp, len(fp) > 0
+			},
+			expect: func(v int) any { return []string{fmt.Sprint(v)} },
+			unset:  []string(nil),
+		},
+		{
+			name: "count",
+			set:  func(err *cluerr.Err, v int) *cluerr.Err { return err.WithCount(v) },
+			get: func(err error) (any, bool) {
+				return cluerr.Count(err)
+			},
+			expect: func(v int) any { return v },
+			unset:  0,
+		},
+		{
+			name: "http status",
+			set:  func(err *cluerr.Err, v int) *cluerr.Err { return err.WithHTTPStatus(400 + v) },
+			get: func(err error) (any, bool) {
+				return cluerr.HTTPStatus(err)
+			},
+			expect: func(v int) any { return 400 + v },
+			unset:  0,
+		},
+		{
+			name: "retry after",
+			set: func(err *cluerr.Err, v int) *cluerr.Err {
+				return err.WithRetryAfter(time.Duration(v) * time.Second)
+			},
+			get: func(err error) (any, bool) {
+				return cluerr.RetryAfter(err)
+			},
+			expect: func(v int) any { return time.Duration(v) * time.Second },
+			unset:  time.Duration(0),
+		},
+		{
+			name: "otel status",
+			set: func(err *cluerr.Err, v int) *cluerr.Err {
+				return err.WithOTELStatus(codes.Error, fmt.Sprint(v))
+			},
+			get: func(err error) (any, bool) {
+				_, desc, ok := cluerr.OTELStatus(err)
+				return desc, ok
+			},
+			expect: func(v int) any { return fmt.Sprint(v) },
+			unset:  "",
+		},
+	}
+
+	type setter func(err *cluerr.Err, v int) *cluerr.Err
+
+	// expect is the int passed to the setter whose value should win,
+	// or 0 if no value should be found.
+	shapes := []struct {
+		name   string
+		err    func(set setter) error
+		expect int
+	}{
+		{"nil", func(set setter) error { return nil }, 0},
+		{"standard error", func(set setter) error { return errors.New("err") }, 0},
+		{"unset", func(set setter) error { return cluerr.New("err") }, 0},
+		{"set", func(set setter) error { return set(cluerr.New("err"), 1) }, 1},
+		{"replaced", func(set setter) error { return set(set(cluerr.New("err"), 1), 2) }, 2},
+		{
+			"wrapped",
+			func(set setter) error { return cluerr.Wrap(set(cluerr.New("err"), 1), "wrap") },
+			1,
+		},
+		{
+			"outermost wins",
+			func(set setter) error { return set(cluerr.Wrap(set(cluerr.New("err"), 1), "wrap"), 2) },
+			2,
+		},
+		{
+			"fmt wrapped",
+			func(set setter) error {
+				return fmt.Errorf("%w", set(cluerr.Wrap(set(cluerr.New("err"), 1), "wrap"), 2))
+			},
+			2,
+		},
+		{
+			"stacked",
+			func(set setter) error { return cluerr.Stack(cluerr.New("a"), set(cluerr.New("b"), 1)) },
+			1,
+		},
+		{
+			"reduced",
+			func(set setter) error {
+				return cluerr.Reduce(cluerr.Wrap(cluerr.Wrap(set(cluerr.New("err"), 1), ""), ""))
+			},
+			1,
+		},
+		{
+			"reduced outermost wins",
+			func(set setter) error {
+				return cluerr.Reduce(set(cluerr.Wrap(cluerr.Wrap(set(cluerr.New("err"), 1), ""), ""), 2))
+			},
+			2,
+		},
+		{
+			"redacted",
+			func(set setter) error { return cluerr.Wrap(set(cluerr.New("err"), 1), "wrap").Redacted() },
+			1,
+		},
+		{
+			"redacted outermost wins",
+			func(set setter) error {
+				return set(cluerr.Wrap(set(cluerr.New("err"), 1), "wrap"), 2).Redacted()
+			},
+			2,
+		},
+	}
+
+	for _, acc := range accessors {
+		for _, shape := range shapes {
+			t.Run(acc.name+"/"+shape.name, func(t *testing.T) {
+				v, ok := acc.get(shape.err(acc.set))
+
+				if shape.expect == 0 {
+					assert.False(t, ok)
+					assert.Equal(t, acc.unset, v)
+
+					return
+				}
+
+				assert.True(t, ok)
+				assert.Equal(t, acc.expect(shape.expect), v)
+			})
+		}
+	}
+
+	// non-positive counts are ignored.
+	_, ok := cluerr.New("err").WithCount(0).Count()
+	assert.False(t, ok)
+
+	// fingerprints are copied, not shared.
+	parts := []string{"a"}
+	err := cluerr.New("err").WithFingerprint(parts...)
+	parts[0] = "changed"
+	err.Fingerprint()[0] = "changed"
+
+	assert.Equal(t, []string{"a"}, err.Fingerprint())
+}
+
+func TestCodeError(t *testing.T) {
+	table := []struct {
+		name   string
+		err    error
+		target string
+		expect assert.BoolAssertionFunc
+	}{
+		{"nil", nil, "c", assert.False},
+		{"standard error", errors.New("err"), "c", assert.False},
+		{"no code", cluerr.New("err"), "c", assert.False},
+		{"no code, empty target", cluerr.New("err"), "", assert.False},
+		{"match", cluerr.New("err").WithCode("c"), "c", assert.True},
+		{"mismatch", cluerr.New("err").WithCode("c"), "d", assert.False},
+		{
+			"wrapped",
+			fmt.Errorf("%w", cluerr.Wrap(cluerr.New("err").WithCode("c"), "wrap")),
+			"c",
+			assert.True,
+		},
+		{
+			"outer code in chain",
+			cluerr.Wrap(cluerr.New("err").WithCode("inner"), "wrap").WithCode("outer"),
+			"outer",
+			assert.True,
+		},
+		{
+			"inner code in chain",
+			cluerr.Wrap(cluerr.New("err").WithCode("inner"), "wrap").WithCode("outer"),
+			"inner",
+			assert.True,
+		},
+		{
+			"stacked",
+			cluerr.Stack(cluerr.New("a"), cluerr.New("b").WithCode("c")),
+			"c",
+			assert.True,
+		},
+		{
+			"stacked mismatch",
+			cluerr.Stack(cluerr.New("a").WithCode("a"), cluerr.New("b").WithCode("b")),
+			"c",
+			assert.False,
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			test.expect(t, errors.Is(test.err, cluerr.CodeError(test.target)))
+		})
+	}
+}
+
+func TestTallyCodes(t *testing.T) {
+	table := []struct {
+		name   string
+		err    error
+		expect map[string]int
+	}{
+		{
+			name:   "nil",
+			err:    nil,
+			expect: map[string]int{},
+		},
+		{
+			name:   "standard error",
+			err:    errors.New("err"),
+			expect: map[string]int{"": 1},
+		},
+		{
+			name:   "single code",
+			err:    cluerr.New("err").WithCode("c"),
+			expect: map[string]int{"c": 1},
+		},
+		{
+			name: "mixed codes",
+			err: cluerr.Stack(
+				cluerr.New("a").WithCode("not_found"),
+				cluerr.New("b").WithCode("not_found"),
+				cluerr.New("c").WithCode("conflict"),
+				cluerr.New("d"),
+				errors.New("e"),
+			),
+			expect: map[string]int{
+				"not_found": 2,
+				"conflict":  1,
+				"":          2,
+			},
+		},
+		{
+			name: "leaves use their own code",
+			err: cluerr.Stack(
+				cluerr.Wrap(errors.New("a"), "wrap").WithCode("wrapped"),
+				fmt.Errorf("%w", cluerr.New("b").WithCode("inner")),
+				cluerr.Wrap(cluerr.New("c").WithCode("inner"), "wrap").WithCode("outer"),
+			).WithCode("combined"),
+			expect: map[string]int{
+				"wrapped": 1,
+				"inner":   2,
+			},
+		},
+		{
+			name: "nested stacks",
+			err: cluerr.Stack(
+				cluerr.Stack(
+					cluerr.New("a").WithCode("x"),
+					cluerr.New("b"),
+				).WithCode("y"),
+				cluerr.New("c").WithCode("x"),
+			),
+			expect: map[string]int{
+				"x": 2,
+				"":  1,
+			},
+		},
+		{
+			name: "wrapped standard errors",
+			err: cluerr.Stack(
+				fmt.Errorf("%w", errors.New("a")),
+				cluerr.Wrap(fmt.Errorf("%w", errors.New("b")), "wrap").WithCode("x"),
+			),
+			expect: map[string]int{
+				"x": 1,
+				"":  1,
+			},
+		},
+		{
+			name: "stacked under a coded parent",
+			err: cluerr.Stack(
+				cluerr.New("a").WithCode("x"),
+				cluerr.New("b"),
+			).WithCode("batch"),
+			expect: map[string]int{
+				"x": 1,
+				"":  1,
+			},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, cluerr.TallyCodes(test.err))
+		})
+	}
+}
+
+func TestWithHTTPStatus(t *testing.T) {
+	table := []struct {
+		name         string
+		err          error
+		expectStatus int
+		expectOK     bool
+		expectLabels msa
+		isRetryable  assert.BoolAssertionFunc
+	}{
+		{
+			name:         "nil",
+			err:          nil,
+			expectLabels: msa{},
+			isRetryable:  assert.False,
+		},
+		{
+			name:         "no status",
+			err:          cluerr.New("err"),
+			expectLabels: msa{},
+			isRetryable:  assert.False,
+		},
+		{
+			name:         "not found",
+			err:          cluerr.New("err").WithHTTPStatus(404),
+			expectStatus: 404,
+			expectOK:     true,
+			expectLabels: msa{cluerr.LabelHTTP4xx: struct{}{}},
+			isRetryable:  assert.False,
+		},
+		{
+			name:         "service unavailable",
+			err:          cluerr.New("err").WithHTTPStatus(503),
+			expectStatus: 503,
+			expectOK:     true,
+			expectLabels: msa{
+				cluerr.LabelHTTP5xx:   struct{}{},
+				cluerr.LabelRetryable: struct{}{},
+			},
+			isRetryable: assert.True,
+		},
+		{
+			name:         "unclassified",
+			err:          cluerr.New("err").WithHTTPStatus(302),
+			expectStatus: 302,
+			expectOK:     true,
+			expectLabels: msa{},
+			isRetryable:  assert.False,
+		},
+		{
+			name:         "wrapped",
+			err:          fmt.Errorf("%w", cluerr.Wrap(cluerr.New("err").WithHTTPStatus(404), "wrap")),
+			expectStatus: 404,
+			expectOK:     true,
+			expectLabels: msa{cluerr.LabelHTTP4xx: struct{}{}},
+			isRetryable:  assert.False,
+		},
+		{
+			name:         "outermost wins",
+			err:          cluerr.Wrap(cluerr.New("err").WithHTTPStatus(503), "wrap").WithHTTPStatus(404),
+			expectStatus: 404,
+			expectOK:     true,
+			expectLabels: msa{
+				cluerr.LabelHTTP4xx:   struct{}{},
+				cluerr.LabelHTTP5xx:   struct{}{},
+				cluerr.LabelRetryable: struct{}{},
+			},
+			isRetryable: assert.True,
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			status, ok := cluerr.HTTPStatus(test.err)
+			assert.Equal(t, test.expectStatus, status)
+			assert.Equal(t, test.expectOK, ok)
+			tester.MustEquals(t, test.expectLabels, toMSA(cluerr.Labels(test.err)), false)
+			test.isRetryable(t, cluerr.HasLabel(test.err, cluerr.LabelRetryable))
+		})
+	}
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	table := []struct {
+		name        string
+		err         error
+		expect      time.Duration
+		expectOK    bool
+		isRetryable assert.BoolAssertionFunc
+	}{
+		{
+			name:        "nil",
+			err:         nil,
+			isRetryable: assert.False,
+		},
+		{
+			name:        "no delay",
+			err:         cluerr.New("err"),
+			isRetryable: assert.False,
+		},
+		{
+			name:        "delay",
+			err:         cluerr.New("err").WithRetryAfter(time.Second),
+			expect:      time.Second,
+			expectOK:    true,
+			isRetryable: assert.True,
+		},
+		{
+			name:        "zero delay",
+			err:         cluerr.New("err").WithRetryAfter(0),
+			expect:      0,
+			expectOK:    true,
+			isRetryable: assert.True,
+		},
+		{
+			name:        "negative delay",
+			err:         cluerr.New("err").WithRetryAfter(-time.Second),
+			expect:      0,
+			expectOK:    true,
+			isRetryable: assert.True,
+		},
+		{
+			name:        "wrapped",
+			err:         fmt.Errorf("%w", cluerr.Wrap(cluerr.New("err").WithRetryAfter(time.Minute), "wrap")),
+			expect:      time.Minute,
+			expectOK:    true,
+			isRetryable: assert.True,
+		},
+		{
+			name: "outermost wins",
+			err: cluerr.Wrap(cluerr.New("err").WithRetryAfter(time.Minute), "wrap").
+				WithRetryAfter(time.Second),
+			expect:      time.Second,
+			expectOK:    true,
+			isRetryable: assert.True,
+		},
+		{
+			name:        "redacted",
+			err:         cluerr.New("err").WithRetryAfter(time.Second).Redacted(),
+			expect:      time.Second,
+			expectOK:    true,
+			isRetryable: assert.True,
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			d, ok := cluerr.RetryAfter(test.err)
+			assert.Equal(t, test.expect, d)
+			assert.Equal(t, test.expectOK, ok)
+			test.isRetryable(t, cluerr.IsRetryable(test.err))
+		})
+	}
+
+	assert.True(t, cluerr.IsRetryable(cluerr.New("err").WithHTTPStatus(503)))
+}
+
+func TestHasLabelPrefix(t *testing.T) {
+	tree := cluerr.Stack(
+		cluerr.Wrap(cluerr.New("inner").Label("db.timeout"), "wrap").Label("net.retry"),
+		cluerr.New("other").Label("db.conn", "dbx"))
+
+	table := []struct {
+		name   string
+		err    error
+		prefix string
+		expect []string
+	}{
+		{"nil", nil, "db.", []string{}},
+		{"no labels", cluerr.New("err"), "db.", []string{}},
+		{"matches", tree, "db.", []string{"db.conn", "db.timeout"}},
+		{"single match", tree, "net.", []string{"net.retry"}},
+		{"no match", tree, "http.", []string{}},
+		{"prefix is not a glob", tree, "db.*", []string{}},
+		{"fmt wrapped", fmt.Errorf("%w", tree), "db.", []string{"db.conn", "db.timeout"}},
+		{"empty prefix", tree, "", []string{"db.conn", "db.timeout", "dbx", "net.retry"}},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, cluerr.LabelsWithPrefix(test.err, test.prefix))
+			assert.Equal(t, len(test.expect) > 0, cluerr.HasLabelPrefix(test.err, test.prefix))
+		})
+	}
+}
+
+func TestLabelWithPrefix(t *testing.T) {
+	chain := cluerr.Wrap(
+		cluerr.Wrap(
+			cluerr.New("inner").Label("http_404", "other"),
+			"middle").Label("http_502", "http_500"),
+		"outer").Label("http_503")
+
+	sentinel := errors.New("prefixed sentinel")
+	cluerr.RegisterSentinel(sentinel, "http_410")
+
+	table := []struct {
+		name        string
+		err         error
+		prefix      string
+		expectFirst string
+		expectLast  string
+		expectOK    bool
+	}{
+		{"nil", nil, "http_", "", "", false},
+		{"no match", chain, "grpc_", "", "", false},
+		{"chain", chain, "http_", "http_503", "http_404", true},
+		{"fmt wrapped", fmt.Errorf("%w", chain), "http_", "http_503", "http_404", true},
+		{"exact label", chain, "other", "other", "other", true},
+		{"empty prefix", chain, "", "http_503", "other", true},
+		{"multiple in one error", cluerr.New("err").Label("http_502", "http_500"), "http_", "http_500", "http_502", true},
+		{"stripped", cluerr.Wrap(chain, "strip").StripLabels().Label("http_400"), "http_", "http_400", "http_400", true},
+		{"sentinel", cluerr.Wrap(sentinel, "wrap").Label("http_400"), "http_", "http_400", "http_410", true},
+		{"stacked sentinel", cluerr.Stack(sentinel, cluerr.New("err").Label("http_400")), "http_", "http_410", "http_400", true},
+		{"clues provider", cluerr.Wrap(providerErr{}, "wrap").Label("pro"), "pro", "pro", "provider", true},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			first, ok := cluerr.FirstLabelWithPrefix(test.err, test.prefix)
+			assert.Equal(t, test.expectFirst, first)
+			assert.Equal(t, test.expectOK, ok)
+
+			last, ok := cluerr.LastLabelWithPrefix(test.err, test.prefix)
+			assert.Equal(t, test.expectLast, last)
+			assert.Equal(t, test.expectOK, ok)
+		})
+	}
+}
+
 func TestLabels(t *testing.T) {
 	var (
 		ma    = msa{"a": struct{}{}}
@@ -343,103 +1014,971 @@ func TestWithMap(t *testing.T) {
 	}
 	for _, test := range table {
 		t.Run(test.name, func(t *testing.T) {
-			err := cluerr.Stack(test.initial).WithMap(test.kv)
-			err = err.WithMap(test.with)
-			tester.MustEquals(t, test.expect, cluerr.CluesIn(err).Map(), false)
-			tester.MustEquals(t, test.expect, err.Values().Map(), false)
+			err := cluerr.Stack(test.initial).WithMap(test.kv)
+			err = err.WithMap(test.with)
+			tester.MustEquals(t, test.expect, cluerr.CluesIn(err).Map(), false)
+			tester.MustEquals(t, test.expect, err.Values().Map(), false)
+		})
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	var (
+		sentinel = errors.New("sentinel")
+		src      = cluerr.Wrap(sentinel, "source").
+				With("k", "src", "src_only", "s").
+				Label("src_label")
+		err = cluerr.New("target").
+			With("k", "target").
+			Label("target_label").
+			Overlay(src)
+	)
+
+	tester.MustEquals(
+		t,
+		msa{
+			"k":        "target",
+			"src_only": "s",
+		},
+		err.Values().Map(),
+		false)
+	tester.MustEquals(
+		t,
+		msa{
+			"src_label":    struct{}{},
+			"target_label": struct{}{},
+		},
+		toMSA(err.Labels()),
+		false)
+
+	assert.Equal(t, "target", err.Error())
+	assert.False(t, errors.Is(err, sentinel), "src is not part of the chain")
+	assert.False(t, errors.Is(err, src), "src is not part of the chain")
+
+	// non-clues errors contribute nothing.
+	plain := cluerr.New("plain").Overlay(sentinel)
+	assert.Empty(t, plain.Values().Map())
+	assert.Empty(t, plain.Labels())
+
+	assert.Equal(t, plain, plain.Overlay(nil))
+
+	var nilErr *cluerr.Err
+	assert.Nil(t, nilErr.Overlay(src))
+}
+
+func TestWithMapPrefixed(t *testing.T) {
+	table := []struct {
+		name    string
+		initial error
+		prefix  string
+		with    msa
+		expect  msa
+	}{
+		{"nil error", nil, "db", msa{"host": "h"}, msa{}},
+		{"nil map", base, "db", nil, msa{}},
+		{"prefixed", base, "db", msa{"host": "h", "port": 1}, msa{"db.host": "h", "db.port": 1}},
+		{"empty prefix", base, "", msa{"host": "h", "port": 1}, msa{"host": "h", "port": 1}},
+		{"wrapped", werr(), "db", msa{"host": "h"}, msa{"db.host": "h", "z": 0}},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			err := cluerr.Stack(test.initial).WithMapPrefixed(test.prefix, test.with)
+			tester.MustEquals(t, test.expect, err.Values().Map(), false)
+		})
+	}
+
+	// empty prefixes match WithMap
+	m := msa{"host": "h"}
+	tester.MustEquals(
+		t,
+		cluerr.New("err").WithMap(m).Values().Map(),
+		cluerr.New("err").WithMapPrefixed("", m).Values().Map(),
+		false)
+}
+
+func TestWithDuration(t *testing.T) {
+	table := []struct {
+		name   string
+		d      time.Duration
+		expect float64
+	}{
+		{"zero", 0, 0},
+		{"milliseconds", 250 * time.Millisecond, 250},
+		{"seconds", 2 * time.Second, 2000},
+		{"fractional", 1500 * time.Microsecond, 1.5},
+		{"negative", -time.Millisecond, -1},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			err := cluerr.New("err").WithDuration("elapsed", test.d)
+
+			v := err.Values().Map()["elapsed"]
+			assert.IsType(t, float64(0), v)
+			assert.Equal(t, test.expect, v)
+		})
+	}
+
+	var nilErr *cluerr.Err
+	assert.Nil(t, nilErr.WithDuration("elapsed", time.Second))
+}
+
+func TestWithErrno(t *testing.T) {
+	table := []struct {
+		name   string
+		sysErr error
+		expect msa
+	}{
+		{
+			name:   "errno",
+			sysErr: syscall.ENOENT,
+			expect: msa{"errno": int(syscall.ENOENT), "errno_name": "ENOENT"},
+		},
+		{
+			name:   "wrapped errno",
+			sysErr: &os.PathError{Op: "open", Path: "/nope", Err: syscall.ENOENT},
+			expect: msa{"errno": int(syscall.ENOENT), "errno_name": "ENOENT"},
+		},
+		{
+			name:   "no errno",
+			sysErr: errors.New("no errno"),
+			expect: msa{},
+		},
+		{
+			name:   "nil",
+			expect: msa{},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			// errno names are only known on unix platforms.
+			if runtime.GOOS == "windows" {
+				delete(test.expect, "errno_name")
+			}
+
+			err := cluerr.Wrap(test.sysErr, "wrap").WithErrno(test.sysErr)
+			if test.sysErr == nil {
+				err = cluerr.New("err").WithErrno(nil)
+			}
+
+			assert.Equal(t, test.expect, toMSA(err.Values().Map()))
+		})
+	}
+
+	var nilErr *cluerr.Err
+	assert.Nil(t, nilErr.WithErrno(syscall.ENOENT))
+}
+
+func tagInHelper(err error) *cluerr.Err {
+	return cluerr.Stack(err).SkipCaller(1).WithCallerTag()
+}
+
+func TestWithContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := cluerr.New("err").WithContextDeadline(ctx)
+
+	v, ok := err.Values().Map()["deadline_remaining_ms"].(float64)
+	require.True(t, ok, "remaining duration is a float64")
+	assert.Greater(t, v, float64(0))
+	assert.LessOrEqual(t, v, float64(time.Minute/time.Millisecond))
+
+	past, cancelPast := context.WithDeadline(
+		context.Background(),
+		time.Now().Add(-time.Second))
+	defer cancelPast()
+
+	err = cluerr.New("err").WithContextDeadline(past)
+	v, ok = err.Values().Map()["deadline_remaining_ms"].(float64)
+	require.True(t, ok, "remaining duration is a float64")
+	assert.Less(t, v, float64(0))
+
+	err = cluerr.New("err").WithContextDeadline(context.Background())
+	assert.NotContains(t, err.Values().Map(), "deadline_remaining_ms")
+
+	var nilErr *cluerr.Err
+	assert.Nil(t, nilErr.WithContextDeadline(ctx))
+}
+
+func TestSinceCtx(t *testing.T) {
+	table := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{
+			name: "timer",
+			ctx:  clues.StartTimer(context.Background(), "start"),
+		},
+		{
+			name: "normalized time",
+			ctx:  clues.Add(context.Background(), "start", time.Now()),
+		},
+		{
+			name: "utc time",
+			ctx:  clues.Add(context.Background(), "start", time.Now().UTC()),
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			time.Sleep(time.Millisecond)
+
+			err := cluerr.New("err").SinceCtx(test.ctx, "start")
+
+			v, ok := err.Values().Map()["start_elapsed_ms"].(float64)
+			require.True(t, ok, "elapsed duration is a float64")
+			assert.Greater(t, v, float64(0))
+		})
+	}
+
+	ctx := clues.Add(context.Background(), "start", "not a time")
+	err := cluerr.New("err").SinceCtx(ctx, "start")
+	assert.NotContains(t, err.Values().Map(), "start_elapsed_ms")
+
+	err = cluerr.New("err").SinceCtx(context.Background(), "start")
+	assert.NotContains(t, err.Values().Map(), "start_elapsed_ms")
+
+	var nilErr *cluerr.Err
+	assert.Nil(t, nilErr.SinceCtx(ctx, "start"))
+}
+
+func TestWithCallerTag(t *testing.T) {
+	err := cluerr.New("err").WithCallerTag()
+	assert.Equal(t, "TestWithCallerTag", err.Values().Map()["caller"])
+
+	err = tagInHelper(cluerr.New("err"))
+	assert.Equal(
+		t,
+		"TestWithCallerTag",
+		err.Values().Map()["caller"],
+		"helper should report its caller")
+
+	var nilErr *cluerr.Err
+	assert.Nil(t, nilErr.WithCallerTag())
+}
+
+func TestWithFieldErrors(t *testing.T) {
+	fe := &cluerr.FieldErrors{}
+	fe.Add("email", "required").
+		Add("age", "must be positive").
+		Add("email", "must be valid")
+
+	assert.Equal(t, 2, fe.Len())
+
+	err := cluerr.New("invalid request").WithFieldErrors(fe)
+	expect := map[string][]string{
+		"email": {"required", "must be valid"},
+		"age":   {"must be positive"},
+	}
+
+	assert.Equal(t, expect, err.Values().Map()[cluerr.FieldErrorsKey])
+	assert.Equal(t, expect, cluerr.ToCore(cluerr.Wrap(err, "wrap")).Values["field_errors"])
+
+	// later additions to the field errors don't alter the error.
+	fe.Add("name", "required")
+	assert.Equal(t, expect, err.Values().Map()[cluerr.FieldErrorsKey])
+
+	// field errors on the same error get merged.
+	more := (&cluerr.FieldErrors{}).Add("email", "already taken")
+	err = err.WithFieldErrors(more)
+	assert.Equal(
+		t,
+		map[string][]string{
+			"email": {"required", "must be valid", "already taken"},
+			"age":   {"must be positive"},
+		},
+		err.Values().Map()[cluerr.FieldErrorsKey])
+
+	// empty field errors are ignored.
+	plain := cluerr.New("plain").WithFieldErrors(&cluerr.FieldErrors{})
+	assert.NotContains(t, plain.Values().Map(), cluerr.FieldErrorsKey)
+
+	var nilErr *cluerr.Err
+	assert.Nil(t, nilErr.WithFieldErrors(fe))
+}
+
+func TestWithTyped(t *testing.T) {
+	err := cluerr.New("err").
+		WithInt("int", 1).
+		WithString("string", "s").
+		WithBool("bool", true).
+		WithFloat64("float64", 1.5)
+
+	vs := cluerr.Wrap(err, "wrap").Values().Map()
+
+	i, ok := vs["int"].(int)
+	assert.True(t, ok, "int type is preserved")
+	assert.Equal(t, 1, i)
+
+	s, ok := vs["string"].(string)
+	assert.True(t, ok, "string type is preserved")
+	assert.Equal(t, "s", s)
+
+	b, ok := vs["bool"].(bool)
+	assert.True(t, ok, "bool type is preserved")
+	assert.True(t, b)
+
+	f, ok := vs["float64"].(float64)
+	assert.True(t, ok, "float64 type is preserved")
+	assert.Equal(t, 1.5, f)
+
+	var nilErr *cluerr.Err
+	assert.Nil(t, nilErr.WithInt("int", 1))
+	assert.Nil(t, nilErr.WithString("string", "s"))
+	assert.Nil(t, nilErr.WithBool("bool", true))
+	assert.Nil(t, nilErr.WithFloat64("float64", 1.5))
+}
+
+func TestWith_rejectEmptyKeys(t *testing.T) {
+	table := []struct {
+		name   string
+		reject bool
+		expect msa
+	}{
+		{"disabled", false, msa{"": "v", "k": "v", "z": 0}},
+		{"enabled", true, msa{"k": "v", "z": 0}},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			clues.SetRejectEmptyKeys(test.reject)
+			defer clues.SetRejectEmptyKeys(false)
+
+			err := cluerr.Stack(werr()).With("", "v", "k", "v")
+			tester.MustEquals(t, test.expect, err.Values().Map(), false)
+
+			err = cluerr.Stack(werr()).WithMap(map[string]any{"": "v", "k": "v"})
+			tester.MustEquals(t, test.expect, err.Values().Map(), false)
+		})
+	}
+}
+
+func TestWithClues(t *testing.T) {
+	ctx := context.Background()
+
+	table := []struct {
+		name    string
+		initial error
+		kv      msa
+		with    msa
+		expect  msa
+	}{
+		{"nil error", nil, msa{"k": "v"}, msa{"k2": "v2"}, msa{}},
+		{"only base error vals", base, msa{"k": "v"}, nil, msa{"k": "v"}},
+		{"empty base error vals", base, msa{"": ""}, nil, msa{"": ""}},
+		{"standard", base, msa{"k": "v"}, msa{"k2": "v2"}, msa{"k": "v", "k2": "v2"}},
+		{"duplicates", base, msa{"k": "v"}, msa{"k": "v2"}, msa{"k": "v2"}},
+		{"multi", base, msa{"a": "1"}, msa{"b": "2", "c": "3"}, msa{"a": "1", "b": "2", "c": "3"}},
+		{"only clue error vals", cerr(), msa{"k": "v"}, nil, msa{"k": "v"}},
+		{"empty clue error vals", cerr(), msa{"": ""}, nil, msa{"": ""}},
+		{"standard cerr", cerr(), msa{"k": "v"}, msa{"k2": "v2"}, msa{"k": "v", "k2": "v2"}},
+		{"duplicates cerr", cerr(), msa{"k": "v"}, msa{"k": "v2"}, msa{"k": "v2"}},
+		{"multi cerr", cerr(), msa{"a": "1"}, msa{"b": "2", "c": "3"}, msa{"a": "1", "b": "2", "c": "3"}},
+		{"only wrapped error vals", werr(), msa{"k": "v"}, nil, msa{"k": "v", "z": 0}},
+		{"empty wrapped error vals", werr(), msa{"": ""}, nil, msa{"": "", "z": 0}},
+		{"standard wrapped", werr(), msa{"k": "v"}, msa{"k2": "v2"}, msa{"k": "v", "k2": "v2", "z": 0}},
+		{"duplicates wrapped", werr(), msa{"k": "v"}, msa{"k": "v2"}, msa{"k": "v2", "z": 0}},
+		{"multi wrapped", werr(), msa{"a": "1"}, msa{"b": "2", "c": "3"}, msa{"a": "1", "b": "2", "c": "3", "z": 0}},
+		{"only stacked error vals", serr(), msa{"k": "v"}, nil, msa{"k": "v", "z": 0}},
+		{"empty stacked error vals", serr(), msa{"": ""}, nil, msa{"": "", "z": 0}},
+		{"standard stacked", serr(), msa{"k": "v"}, msa{"k2": "v2"}, msa{"k": "v", "k2": "v2", "z": 0}},
+		{"duplicates stacked", serr(), msa{"k": "v"}, msa{"k": "v2"}, msa{"k": "v2", "z": 0}},
+		{"multi stacked", serr(), msa{"a": "1"}, msa{"b": "2", "c": "3"}, msa{"a": "1", "b": "2", "c": "3", "z": 0}},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			tctx := clues.AddMap(ctx, test.kv)
+			err := cluerr.Stack(test.initial).WithClues(tctx)
+			err = err.WithMap(test.with)
+			tester.MustEquals(t, test.expect, cluerr.CluesIn(err).Map(), false)
+			tester.MustEquals(t, test.expect, err.Values().Map(), false)
+		})
+	}
+}
+
+func TestUserValues(t *testing.T) {
+	ctx := clues.AddSpan(context.Background(), "span", "ctx_k", "ctx_v")
+	ctx = clues.AddAgent(ctx, "agent")
+	clues.Relay(ctx, "agent", "ak", "av")
+
+	err := cluerr.NewWC(ctx, "err").With("k", "v")
+
+	all := err.Values().Map()
+	require.Contains(t, all, clues.TraceKey)
+	require.Contains(t, all, "agents")
+
+	assert.Equal(
+		t,
+		map[string]any{"ctx_k": "ctx_v", "k": "v"},
+		err.UserValues())
+
+	// respects a renamed trace key
+	clues.SetTraceKey("trace_ids")
+	defer clues.SetTraceKey("")
+
+	err = cluerr.NewWC(ctx, "err")
+	require.Contains(t, err.Values().Map(), "trace_ids")
+	assert.Equal(t, map[string]any{"ctx_k": "ctx_v"}, err.UserValues())
+
+	var nilErr *cluerr.Err
+	assert.Empty(t, nilErr.UserValues())
+}
+
+func TestTraceID(t *testing.T) {
+	var (
+		provider = sdkTrace.NewTracerProvider()
+		ctx, sp  = provider.Tracer("test").Start(context.Background(), "span")
+		expect   = sp.SpanContext().TraceID().String()
+	)
+
+	defer sp.End()
+
+	table := []struct {
+		name   string
+		err    error
+		expect string
+	}{
+		{"nil", nil, ""},
+		{"no ctx", cluerr.New("err"), ""},
+		{"no span", cluerr.NewWC(context.Background(), "err"), ""},
+		{"new", cluerr.NewWC(ctx, "err"), expect},
+		{"wrap", cluerr.WrapWC(ctx, errors.New("err"), "wrap"), expect},
+		{"stack", cluerr.StackWC(ctx, errors.New("err")), expect},
+		{"wrapped", cluerr.Wrap(cluerr.NewWC(ctx, "err"), "wrap"), expect},
+		{"fmt wrapped", fmt.Errorf("%w", cluerr.NewWC(ctx, "err")), expect},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, cluerr.TraceID(test.err))
+		})
+	}
+
+	err := cluerr.NewWC(ctx, "err").With("k", "v")
+	assert.Equal(t, expect, err.TraceID())
+	assert.Equal(t, map[string]any{"k": "v"}, err.UserValues(), "the trace id is reserved")
+}
+
+func TestWithRequestIDFromCtx(t *testing.T) {
+	table := []struct {
+		name   string
+		ctx    func() context.Context
+		expect msa
+	}{
+		{
+			name:   "no clues",
+			ctx:    context.Background,
+			expect: msa{"k": "v"},
+		},
+		{
+			name: "no request id",
+			ctx: func() context.Context {
+				return clues.Add(context.Background(), "other", "value")
+			},
+			expect: msa{"k": "v"},
+		},
+		{
+			name: "request id",
+			ctx: func() context.Context {
+				return clues.Add(context.Background(), cluerr.RequestIDKey, "rid", "other", "value")
+			},
+			expect: msa{"k": "v", cluerr.RequestIDKey: "rid"},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			err := cluerr.New("err").With("k", "v").WithRequestIDFromCtx(test.ctx())
+			tester.MustEquals(t, test.expect, err.Values().Map(), false)
+		})
+	}
+}
+
+func TestSetIncludeBaggage(t *testing.T) {
+	ctx := clues.Add(context.Background(), "k", "v")
+
+	ctx, err := clues.AddBaggage(ctx, "tenant_id", "tid")
+	require.NoError(t, err)
+
+	ctx, err = clues.AddBaggage(ctx, "user_id", "uid")
+	require.NoError(t, err)
+
+	// disabled by default
+	assert.Equal(t, map[string]any{"k": "v"}, cluerr.NewWC(ctx, "err").Values().Map())
+
+	cluerr.SetIncludeBaggage(true)
+	defer cluerr.SetIncludeBaggage(false)
+
+	expect := map[string]any{
+		"k":                 "v",
+		"baggage.tenant_id": "tid",
+		"baggage.user_id":   "uid",
+	}
+
+	assert.Equal(t, expect, cluerr.NewWC(ctx, "err").Values().Map())
+	assert.Equal(t, expect, cluerr.WrapWC(ctx, errors.New("err"), "wrap").Values().Map())
+
+	// no baggage, no additions
+	assert.Equal(
+		t,
+		map[string]any{"k": "v"},
+		cluerr.NewWC(clues.Add(context.Background(), "k", "v"), "err").Values().Map())
+}
+
+func TestValuePriority(t *testing.T) {
+	table := []struct {
+		name   string
+		err    error
+		expect msa
+	}{
+		{
+			name: "lowest data wins",
+			err: func() error {
+				ctx := clues.Add(context.Background(), "in-ctx", 1)
+				// the last addition to a ctx should take priority
+				ctx = clues.Add(ctx, "in-ctx", 2)
+
+				err := cluerr.NewWC(ctx, "err").With("in-err", 1)
+				// the first addition to an error should take priority
+				err = cluerr.StackWC(ctx, err).With("in-err", 2)
+
+				return err
+			}(),
+			expect: msa{"in-ctx": 2, "in-err": 1},
+		},
+		{
+			name: "last stack wins",
+			err: func() error {
+				ctx := clues.Add(context.Background(), "in-ctx", 1)
+				err := cluerr.NewWC(ctx, "last in stack").With("in-err", 1)
+				err = cluerr.Stack(
+					cluerr.New("first in stack").With("in-err", 2),
+					err)
+				return err
+			}(),
+			expect: msa{"in-ctx": 1, "in-err": 1},
+		},
+		{
+			name: ".With wins over ctx",
+			err: func() error {
+				ctx := clues.Add(context.Background(), "k", 1)
+				err := cluerr.NewWC(ctx, "last in stack").With("k", 2)
+				return err
+			}(),
+			expect: msa{"k": 2},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			tester.MustEquals(t, test.expect, cluerr.CluesIn(test.err).Map(), false)
+		})
+	}
+}
+
+func TestValuesWith(t *testing.T) {
+	var (
+		sentinel = cluerr.New("sentinel").With("k", "root", "root_only", 1)
+		wrapped  = cluerr.Wrap(sentinel, "wrap").With("k", "wrap")
+		err      = cluerr.Stack(
+			cluerr.New("first").With("s", "first"),
+			cluerr.New("last").With("s", "last"),
+			wrapped,
+		).With("k", "outer")
+	)
+
+	table := []struct {
+		name   string
+		opts   []cluerr.ValuesOption
+		expect msa
+	}{
+		{
+			name:   "default",
+			expect: msa{"k": "root", "root_only": 1, "s": "last"},
+		},
+		{
+			name:   "root wins",
+			opts:   []cluerr.ValuesOption{cluerr.RootWins()},
+			expect: msa{"k": "root", "root_only": 1, "s": "last"},
+		},
+		{
+			name:   "outermost wins",
+			opts:   []cluerr.ValuesOption{cluerr.OutermostWins()},
+			expect: msa{"k": "outer", "root_only": 1, "s": "first"},
+		},
+		{
+			name:   "last option wins",
+			opts:   []cluerr.ValuesOption{cluerr.OutermostWins(), cluerr.RootWins()},
+			expect: msa{"k": "root", "root_only": 1, "s": "last"},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			tester.MustEquals(t, test.expect, err.ValuesWith(test.opts...).Map(), false)
+		})
+	}
+
+	// default matches Values()
+	tester.MustEquals(t, err.Values().Map(), err.ValuesWith().Map(), false)
+
+	// wrapping a non-clues error doesn't interfere with precedence.
+	fmted := cluerr.Wrap(fmt.Errorf("%w", wrapped), "top").With("k", "top")
+	tester.MustEquals(
+		t,
+		msa{"k": "top", "root_only": 1},
+		fmted.ValuesWith(cluerr.OutermostWins()).Map(),
+		false)
+
+	var nilErr *cluerr.Err
+	assert.Empty(t, nilErr.ValuesWith(cluerr.OutermostWins()).Map())
+}
+
+func TestAll(t *testing.T) {
+	var (
+		a       = cluerr.New("a")
+		b       = errors.New("b")
+		wrapped = cluerr.Wrap(b, "w")
+		fmted   = fmt.Errorf("%w", wrapped)
+		err     = cluerr.Stack(a, fmted)
+		expect  = []error{b, wrapped, fmted, a, err}
+	)
+
+	got := []error{}
+
+	for en := range err.All() {
+		got = append(got, en.Err)
+
+		ce, ok := en.Err.(*cluerr.Err)
+		if ok != (en.Clues != nil) || (ok && ce != en.Clues) {
+			t.Errorf("expected node clues [%v] to match node error [%v]", en.Clues, en.Err)
+		}
+	}
+
+	if len(got) != len(expect) {
+		t.Fatalf("expected [%d] nodes, got [%d]: %v", len(expect), len(got), got)
+	}
+
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Errorf("expected node [%d] to be [%v], got [%v]", i, expect[i], got[i])
+		}
+	}
+
+	// breaking early stops the iteration
+	count := 0
+
+	for range err.All() {
+		count++
+		break
+	}
+
+	if count != 1 {
+		t.Errorf("expected a single iteration, got [%d]", count)
+	}
+
+	var nilErr *cluerr.Err
+
+	for en := range nilErr.All() {
+		t.Errorf("expected no nodes in a nil error, got %v", en)
+	}
+}
+
+func TestWalkDepth(t *testing.T) {
+	type visit struct {
+		depth int
+		msg   string
+	}
+
+	// the "double double animal wrap"
+	err := cluerr.Stack(
+		cluerr.Wrap(
+			cluerr.Stack(
+				cluerr.New("top").With("k", "v"),
+				cluerr.New("left").With("k2", "v2"),
+			),
+			"left-stack"),
+		cluerr.Wrap(
+			cluerr.Stack(
+				cluerr.New("right").With("k3", "v3"),
+				cluerr.New("base").With("k4", "v4"),
+			),
+			"right-stack"),
+	)
+
+	expect := []visit{
+		{0, "left-stack: top: left: right-stack: right: base"},
+		{1, "right-stack: right: base"},
+		{2, "right: base"},
+		{3, "base"},
+		{3, "right"},
+		{1, "left-stack: top: left"},
+		{2, "top: left"},
+		{3, "left"},
+		{3, "top"},
+	}
+
+	got := []visit{}
+
+	cluerr.WalkDepth(err, func(depth int, en cluerr.ErrNode) bool {
+		assert.Equal(t, en.Err, en.Clues)
+
+		got = append(got, visit{depth, en.Err.Error()})
+
+		return true
+	})
+
+	assert.Equal(t, expect, got)
+
+	// non-clues errors are walked through their wrappers.
+	base := errors.New("base")
+	got = []visit{}
+
+	cluerr.WalkDepth(
+		fmt.Errorf("fmt: %w", cluerr.Wrap(base, "wrap")),
+		func(depth int, en cluerr.ErrNode) bool {
+			got = append(got, visit{depth, en.Err.Error()})
+			return true
+		})
+
+	assert.Equal(
+		t,
+		[]visit{
+			{0, "fmt: wrap: base"},
+			{1, "wrap: base"},
+			{2, "base"},
+		},
+		got)
+
+	// returning false stops the walk.
+	count := 0
+
+	cluerr.WalkDepth(err, func(int, cluerr.ErrNode) bool {
+		count++
+		return count < 3
+	})
+
+	assert.Equal(t, 3, count)
+
+	cluerr.WalkDepth(nil, func(int, cluerr.ErrNode) bool {
+		t.Error("expected no nodes in a nil error")
+		return true
+	})
+}
+
+func TestMessageChain(t *testing.T) {
+	table := []struct {
+		name   string
+		err    error
+		expect []string
+	}{
+		{"nil", nil, []string{}},
+		{"standard error", errors.New("base"), []string{"base"}},
+		{"new", cluerr.New("new"), []string{"new"}},
+		{"wrapped", cluerr.Wrap(errors.New("base"), "wrap"), []string{"wrap", "base"}},
+		{
+			"fmt wrapped",
+			fmt.Errorf("fmt: %w", cluerr.Wrap(errors.New("base"), "wrap")),
+			[]string{"fmt", "wrap", "base"},
+		},
+		{
+			"stacked",
+			cluerr.Stack(cluerr.New("a"), cluerr.New("b")),
+			[]string{"a", "b"},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, cluerr.MessageChain(test.err))
+		})
+	}
+}
+
+func TestMessageContains(t *testing.T) {
+	err := cluerr.Wrap(errors.New("bar baz"), "foo")
+
+	table := []struct {
+		name   string
+		substr string
+		expect bool
+	}{
+		{"within first segment", "fo", true},
+		{"within second segment", "bar b", true},
+		{"whole segment", "bar baz", true},
+		{"spans segments", "foo: bar", false},
+		{"spans segments without delimiter", "o: b", false},
+		{"missing", "qux", false},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, err.MessageContains(test.substr))
+			assert.Equal(t, test.expect, cluerr.MessageContains(fmt.Errorf("%w", err), test.substr))
+		})
+	}
+}
+
+func TestMatchMessageAndLabels(t *testing.T) {
+	build := func(ts time.Time, labels ...string) error {
+		base := cluerr.New("base").
+			With("timestamp", ts).
+			Label(labels...)
+
+		return cluerr.Wrap(base, "wrap").With("now", ts.String())
+	}
+
+	var (
+		now   = time.Now()
+		later = now.Add(time.Hour)
+	)
+
+	table := []struct {
+		name   string
+		a, b   error
+		expect assert.BoolAssertionFunc
+	}{
+		{"both nil", nil, nil, assert.True},
+		{"one nil", build(now), nil, assert.False},
+		{"identical", build(now, "l"), build(now, "l"), assert.True},
+		{"differing values", build(now, "l"), build(later, "l"), assert.True},
+		{"differing labels", build(now, "l"), build(now, "other"), assert.False},
+		{"missing labels", build(now, "l"), build(now), assert.False},
+		{
+			"differing messages",
+			build(now),
+			cluerr.Wrap(cluerr.New("base"), "other"),
+			assert.False,
+		},
+		{
+			"differing depth",
+			build(now),
+			cluerr.Wrap(build(now), "outer"),
+			assert.False,
+		},
+		{
+			"std lib wrapping",
+			fmt.Errorf("fmt: %w", build(now, "l")),
+			fmt.Errorf("fmt: %w", build(later, "l")),
+			assert.True,
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			test.expect(t, cluerr.MatchMessageAndLabels(test.a, test.b))
+			test.expect(t, cluerr.MatchMessageAndLabels(test.b, test.a))
 		})
 	}
 }
 
-func TestWithClues(t *testing.T) {
-	ctx := context.Background()
+func countNodes(err *cluerr.Err) int {
+	var n int
+
+	for range err.All() {
+		n++
+	}
+
+	return n
+}
+
+func TestReduce(t *testing.T) {
+	sentinel := errors.New("sentinel")
 
 	table := []struct {
-		name    string
-		initial error
-		kv      msa
-		with    msa
-		expect  msa
+		name        string
+		err         func() *cluerr.Err
+		expectNodes int
 	}{
-		{"nil error", nil, msa{"k": "v"}, msa{"k2": "v2"}, msa{}},
-		{"only base error vals", base, msa{"k": "v"}, nil, msa{"k": "v"}},
-		{"empty base error vals", base, msa{"": ""}, nil, msa{"": ""}},
-		{"standard", base, msa{"k": "v"}, msa{"k2": "v2"}, msa{"k": "v", "k2": "v2"}},
-		{"duplicates", base, msa{"k": "v"}, msa{"k": "v2"}, msa{"k": "v2"}},
-		{"multi", base, msa{"a": "1"}, msa{"b": "2", "c": "3"}, msa{"a": "1", "b": "2", "c": "3"}},
-		{"only clue error vals", cerr(), msa{"k": "v"}, nil, msa{"k": "v"}},
-		{"empty clue error vals", cerr(), msa{"": ""}, nil, msa{"": ""}},
-		{"standard cerr", cerr(), msa{"k": "v"}, msa{"k2": "v2"}, msa{"k": "v", "k2": "v2"}},
-		{"duplicates cerr", cerr(), msa{"k": "v"}, msa{"k": "v2"}, msa{"k": "v2"}},
-		{"multi cerr", cerr(), msa{"a": "1"}, msa{"b": "2", "c": "3"}, msa{"a": "1", "b": "2", "c": "3"}},
-		{"only wrapped error vals", werr(), msa{"k": "v"}, nil, msa{"k": "v", "z": 0}},
-		{"empty wrapped error vals", werr(), msa{"": ""}, nil, msa{"": "", "z": 0}},
-		{"standard wrapped", werr(), msa{"k": "v"}, msa{"k2": "v2"}, msa{"k": "v", "k2": "v2", "z": 0}},
-		{"duplicates wrapped", werr(), msa{"k": "v"}, msa{"k": "v2"}, msa{"k": "v2", "z": 0}},
-		{"multi wrapped", werr(), msa{"a": "1"}, msa{"b": "2", "c": "3"}, msa{"a": "1", "b": "2", "c": "3", "z": 0}},
-		{"only stacked error vals", serr(), msa{"k": "v"}, nil, msa{"k": "v", "z": 0}},
-		{"empty stacked error vals", serr(), msa{"": ""}, nil, msa{"": "", "z": 0}},
-		{"standard stacked", serr(), msa{"k": "v"}, msa{"k2": "v2"}, msa{"k": "v", "k2": "v2", "z": 0}},
-		{"duplicates stacked", serr(), msa{"k": "v"}, msa{"k": "v2"}, msa{"k": "v2", "z": 0}},
-		{"multi stacked", serr(), msa{"a": "1"}, msa{"b": "2", "c": "3"}, msa{"a": "1", "b": "2", "c": "3", "z": 0}},
+		{
+			name:        "no no-ops",
+			err:         func() *cluerr.Err { return cluerr.Wrap(cluerr.Wrap(sentinel, "a"), "b") },
+			expectNodes: 3,
+		},
+		{
+			name: "consecutive no-ops",
+			err: func() *cluerr.Err {
+				return cluerr.Wrap(cluerr.Wrap(cluerr.Wrap(cluerr.New("base"), ""), ""), "")
+			},
+			expectNodes: 2,
+		},
+		{
+			name: "interrupted no-ops",
+			err: func() *cluerr.Err {
+				err := cluerr.Wrap(cluerr.Wrap(sentinel, ""), "")
+				err = cluerr.Wrap(cluerr.Wrap(err, "msg").Label("l"), "")
+				return cluerr.Wrap(cluerr.Wrap(err, "").With("k", "v"), "")
+			},
+			expectNodes: 6,
+		},
+		{
+			name: "stacked",
+			err: func() *cluerr.Err {
+				return cluerr.Wrap(cluerr.Wrap(cluerr.Stack(sentinel, cluerr.New("other")), ""), "")
+			},
+			expectNodes: 4,
+		},
 	}
 	for _, test := range table {
 		t.Run(test.name, func(t *testing.T) {
-			tctx := clues.AddMap(ctx, test.kv)
-			err := cluerr.Stack(test.initial).WithClues(tctx)
-			err = err.WithMap(test.with)
-			tester.MustEquals(t, test.expect, cluerr.CluesIn(err).Map(), false)
-			tester.MustEquals(t, test.expect, err.Values().Map(), false)
+			var (
+				err     = test.err()
+				before  = countNodes(err)
+				reduced = cluerr.Reduce(err)
+			)
+
+			assert.Equal(t, test.expectNodes, countNodes(reduced))
+			assert.Equal(t, before, countNodes(err), "original error is not modified")
+
+			assert.Equal(t, err.Error(), reduced.Error())
+			assert.Equal(t, cluerr.MessageChain(err), cluerr.MessageChain(reduced))
+			assert.Equal(t, cluerr.Labels(err), cluerr.Labels(reduced))
+			assert.Equal(t, err.Values().Map(), reduced.Values().Map())
+			assert.Equal(t, errors.Is(err, sentinel), errors.Is(reduced, sentinel))
 		})
 	}
+
+	assert.Nil(t, cluerr.Reduce(nil))
+	assert.Equal(t, 2, countNodes(cluerr.Reduce(sentinel)))
 }
 
-func TestValuePriority(t *testing.T) {
+func TestReduce_keepsMetadata(t *testing.T) {
 	table := []struct {
 		name   string
-		err    error
-		expect msa
+		set    func(*cluerr.Err) *cluerr.Err
+		getter func(error) any
 	}{
 		{
-			name: "lowest data wins",
-			err: func() error {
-				ctx := clues.Add(context.Background(), "in-ctx", 1)
-				// the last addition to a ctx should take priority
-				ctx = clues.Add(ctx, "in-ctx", 2)
-
-				err := cluerr.NewWC(ctx, "err").With("in-err", 1)
-				// the first addition to an error should take priority
-				err = cluerr.StackWC(ctx, err).With("in-err", 2)
-
-				return err
-			}(),
-			expect: msa{"in-ctx": 2, "in-err": 1},
+			name: "count",
+			set:  func(err *cluerr.Err) *cluerr.Err { return err.WithCount(3) },
+			getter: func(err error) any {
+				n, ok := cluerr.Count(err)
+				return []any{n, ok}
+			},
 		},
 		{
-			name: "last stack wins",
-			err: func() error {
-				ctx := clues.Add(context.Background(), "in-ctx", 1)
-				err := cluerr.NewWC(ctx, "last in stack").With("in-err", 1)
-				err = cluerr.Stack(
-					cluerr.New("first in stack").With("in-err", 2),
-					err)
-				return err
-			}(),
-			expect: msa{"in-ctx": 1, "in-err": 1},
+			name:   "fingerprint",
+			set:    func(err *cluerr.Err) *cluerr.Err { return err.WithFingerprint("fp") },
+			getter: func(err error) any { return cluerr.Fingerprint(err) },
 		},
 		{
-			name: ".With wins over ctx",
-			err: func() error {
-				ctx := clues.Add(context.Background(), "k", 1)
-				err := cluerr.NewWC(ctx, "last in stack").With("k", 2)
-				return err
-			}(),
-			expect: msa{"k": 2},
+			name: "otel status",
+			set:  func(err *cluerr.Err) *cluerr.Err { return err.WithOTELStatus(codes.Ok, "ok") },
+			getter: func(err error) any {
+				code, desc, ok := cluerr.OTELStatus(err)
+				return []any{code, desc, ok}
+			},
+		},
+		{
+			name: "retry after",
+			set:  func(err *cluerr.Err) *cluerr.Err { return err.WithRetryAfter(time.Second) },
+			getter: func(err error) any {
+				d, ok := cluerr.RetryAfter(err)
+				return []any{d, ok}
+			},
 		},
 	}
 	for _, test := range table {
 		t.Run(test.name, func(t *testing.T) {
-			tester.MustEquals(t, test.expect, cluerr.CluesIn(test.err).Map(), false)
+			var (
+				base    = cluerr.New("base")
+				err     = cluerr.Wrap(test.set(cluerr.Wrap(base, "")), "")
+				reduced = cluerr.Reduce(err)
+			)
+
+			assert.Equal(t, test.getter(err), test.getter(reduced))
+			assert.Equal(t, 3, countNodes(reduced), "the error holding the metadata is kept")
 		})
 	}
 }
@@ -461,6 +2000,272 @@ func TestUnwrap(t *testing.T) {
 	}
 }
 
+func TestSetTraceProvider(t *testing.T) {
+	var depths []int
+
+	clues.SetTraceProvider(func(depth int) clues.Frame {
+		depths = append(depths, depth)
+
+		return clues.Frame{
+			Func: "github.com/alcionai/stub.stubFunc",
+			File: "/stub/dir/stub.go",
+			Line: 42,
+		}
+	})
+	defer clues.SetTraceProvider(nil)
+
+	out := fmt.Sprintf("%+v", cluerr.New("stubbed"))
+
+	assert.Contains(t, out, "stubFunc - dir/stub.go:42", "trace uses the stub provider")
+	require.NotEmpty(t, depths, "stub provider is called")
+
+	clues.SetTraceProvider(nil)
+
+	out = fmt.Sprintf("%+v", cluerr.New("default"))
+
+	assert.Contains(t, out, "TestSetTraceProvider - cluerr/err_test.go:", "trace uses the default provider")
+}
+
+func TestCause(t *testing.T) {
+	e := errors.New("cause")
+	we := cluerr.Wrap(e, "outer")
+
+	ce := we.Cause()
+	if ce != e {
+		t.Errorf("expected result error [%v] to be base error [%v]\n", ce, e)
+	}
+
+	ce = errors.Cause(we)
+	if ce != e {
+		t.Errorf("expected pkg/errors cause [%v] to be base error [%v]\n", ce, e)
+	}
+
+	ce = errors.Cause(cluerr.Stack(cluerr.Wrap(we, "outermost")))
+	if ce != e {
+		t.Errorf("expected nested pkg/errors cause [%v] to be base error [%v]\n", ce, e)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	var (
+		inner = cluerr.Wrap(base, "inner").With("k", "v").Label("l")
+		other = fmt.Errorf("%w", cluerr.New("other").With("k2", "v2"))
+		err   = cluerr.Stack(inner, other).
+			With("top", "t").
+			Label("top").
+			Comment("a comment about %s", "v")
+		expectVals = msa{
+			"k":   cecrets.Conceal("v"),
+			"k2":  cecrets.Conceal("v2"),
+			"top": cecrets.Conceal("t"),
+		}
+	)
+
+	red := err.Redacted()
+
+	if red.Error() != err.Error() {
+		t.Errorf("expected message [%s], got [%s]", err.Error(), red.Error())
+	}
+
+	tester.MustEquals(t, toMSA(err.Labels()), toMSA(red.Labels()), false)
+	tester.MustEquals(t, expectVals, red.Values().Map(), false)
+	tester.MustEquals(t, expectVals, cluerr.CluesIn(red).Map(), false)
+
+	if len(red.Comments()) > 0 {
+		t.Errorf("expected no comments, got %v", red.Comments())
+	}
+
+	if !errors.Is(red, base) {
+		t.Error("expected redacted error to retain the base error")
+	}
+
+	// the original error is unchanged
+	tester.MustEquals(t, msa{"k": "v", "k2": "v2", "top": "t"}, err.Values().Map(), false)
+
+	var nilErr *cluerr.Err
+	if nilErr.Redacted() != nil {
+		t.Error("expected nil error to redact to nil")
+	}
+}
+
+func TestRedacted_reservedKeys(t *testing.T) {
+	ctx := clues.Add(context.Background(), "k", "v")
+	ctx = clues.AddComment(ctx, "produces a node id for the trace")
+	ctx = clues.AddAgent(ctx, "agent")
+	clues.Relay(ctx, "agent", "ak", "av")
+
+	var (
+		err  = cluerr.NewWC(ctx, "err")
+		vals = err.Values().Map()
+		red  = err.Redacted().Values().Map()
+	)
+
+	require.NotEmpty(t, vals["clues_trace"])
+	assert.Equal(t, vals["clues_trace"], red["clues_trace"], "the trace is not concealed")
+	assert.Equal(t, vals["agents"], red["agents"], "agent values are not concealed")
+	assert.Equal(t, cecrets.Conceal("v"), red["k"])
+}
+
+func TestSanitize(t *testing.T) {
+	var (
+		inner = cluerr.Wrap(base, "inner").With("k", "v", "secret", "s").Label("l")
+		other = fmt.Errorf("%w", cluerr.New("other").With("k2", "v2"))
+		err   = cluerr.Stack(inner, other).
+			With("top", "t").
+			Label("top").
+			Comment("a comment about %s", "s")
+	)
+
+	san := err.Sanitize("k", "top", "missing")
+
+	if san.Error() != err.Error() {
+		t.Errorf("expected message [%s], got [%s]", err.Error(), san.Error())
+	}
+
+	tester.MustEquals(t, toMSA(err.Labels()), toMSA(san.Labels()), false)
+	tester.MustEquals(t, msa{"k": "v", "top": "t"}, san.Values().Map(), false)
+	tester.MustEquals(t, msa{"k": "v", "top": "t"}, cluerr.CluesIn(san).Map(), false)
+
+	if len(san.Comments()) > 0 {
+		t.Errorf("expected no comments, got %v", san.Comments())
+	}
+
+	if !errors.Is(san, base) {
+		t.Error("expected sanitized error to retain the base error")
+	}
+
+	// no allowed keys removes all values
+	tester.MustEquals(t, msa{}, err.Sanitize().Values().Map(), false)
+
+	// reserved values are always retained
+	traced := cluerr.NewWC(clues.AddComment(context.Background(), "c"), "err")
+	assert.Equal(
+		t,
+		traced.Values().Map()["clues_trace"],
+		traced.Sanitize().Values().Map()["clues_trace"])
+
+	// the original error is unchanged
+	tester.MustEquals(
+		t,
+		msa{"k": "v", "secret": "s", "k2": "v2", "top": "t"},
+		err.Values().Map(),
+		false)
+
+	var nilErr *cluerr.Err
+	if nilErr.Sanitize("k") != nil {
+		t.Error("expected nil error to sanitize to nil")
+	}
+}
+
+func TestTransformValues(t *testing.T) {
+	var (
+		inner = cluerr.Wrap(base, "inner").With("k", "v", "n", 1).Label("l")
+		other = fmt.Errorf("%w", cluerr.New("other").With("k2", "v2"))
+		err   = cluerr.Stack(inner, other).
+			With("top", "t").
+			Label("top")
+	)
+
+	tx := err.TransformValues(func(k string, v any) (string, any) {
+		return "x_" + k, fmt.Sprintf("%v!", v)
+	})
+
+	if tx.Error() != err.Error() {
+		t.Errorf("expected message [%s], got [%s]", err.Error(), tx.Error())
+	}
+
+	tester.MustEquals(t, toMSA(err.Labels()), toMSA(tx.Labels()), false)
+	tester.MustEquals(
+		t,
+		msa{"x_k": "v!", "x_n": "1!", "x_k2": "v2!", "x_top": "t!"},
+		tx.Values().Map(),
+		false)
+
+	if !errors.Is(tx, base) {
+		t.Error("expected transformed error to retain the base error")
+	}
+
+	// a nil func copies the values as-is
+	tester.MustEquals(
+		t,
+		msa{"k": "v", "n": 1, "k2": "v2", "top": "t"},
+		err.TransformValues(nil).Values().Map(),
+		false)
+
+	// the original error is unchanged
+	tester.MustEquals(
+		t,
+		msa{"k": "v", "n": 1, "k2": "v2", "top": "t"},
+		err.Values().Map(),
+		false)
+
+	var nilErr *cluerr.Err
+	if nilErr.TransformValues(nil) != nil {
+		t.Error("expected nil error to transform to nil")
+	}
+}
+
+func TestTransformValues_reservedKeys(t *testing.T) {
+	var (
+		ctx  = clues.AddComment(clues.Add(context.Background(), "k", "v"), "c")
+		err  = cluerr.NewWC(ctx, "err")
+		seen = []string{}
+	)
+
+	tx := err.TransformValues(func(k string, v any) (string, any) {
+		seen = append(seen, k)
+		// attempt to clobber the trace.
+		return "clues_trace", v
+	})
+
+	assert.Equal(t, []string{"k"}, seen, "reserved keys are not passed to fn")
+	assert.Equal(t, err.Values().Map()["clues_trace"], tx.Values().Map()["clues_trace"])
+}
+
+func TestPrune(t *testing.T) {
+	var (
+		large = strings.Repeat("x", 100)
+		inner = cluerr.Wrap(base, "inner").With("large", large).Label("l")
+		err   = cluerr.Wrap(inner, "outer").With("small", "s")
+	)
+
+	pruned := err.Prune(10)
+
+	if pruned.Error() != err.Error() {
+		t.Errorf("expected message [%s], got [%s]", err.Error(), pruned.Error())
+	}
+
+	tester.MustEquals(t, toMSA(err.Labels()), toMSA(pruned.Labels()), false)
+	tester.MustEquals(
+		t,
+		msa{"large": "<pruned, 100 bytes>", "small": "s"},
+		pruned.Values().Map(),
+		false)
+
+	if !errors.Is(pruned, base) {
+		t.Error("expected pruned error to retain the base error")
+	}
+
+	// the original error is unchanged
+	tester.MustEquals(t, msa{"large": large, "small": "s"}, err.Values().Map(), false)
+
+	// non-positive limits don't prune
+	tester.MustEquals(t, msa{"large": large, "small": "s"}, err.Prune(0).Values().Map(), false)
+
+	// reserved values are never pruned
+	ctx := clues.AddComment(context.Background(), "c")
+	traced := cluerr.NewWC(ctx, "err")
+	assert.Equal(
+		t,
+		traced.Values().Map()["clues_trace"],
+		traced.Prune(1).Values().Map()["clues_trace"])
+
+	var nilErr *cluerr.Err
+	if nilErr.Prune(10) != nil {
+		t.Error("expected nil error to prune to nil")
+	}
+}
+
 func TestWrapNilStackSlice(t *testing.T) {
 	// an empty slice of errors
 	sl := make([]error, 10)
@@ -685,6 +2490,165 @@ func TestErrValues_stacks(t *testing.T) {
 	}
 }
 
+func TestCombine(t *testing.T) {
+	var (
+		left = cluerr.Stack(
+			cluerr.New("top").With("k", "v").Label("top"),
+			cluerr.New("left").With("k2", "v2").Label("left"),
+		).Comment("left comment")
+		right = cluerr.Wrap(
+			cluerr.Stack(
+				cluerr.New("right").With("k3", "v3").Label("right"),
+				cluerr.New("base").With("k", "v4").Label("base"),
+			),
+			"right-stack",
+		).Comment("right comment")
+		other = errors.New("other")
+	)
+
+	err := cluerr.Combine(left, nil, right, other)
+
+	if err.Error() != cluerr.Stack(left, right, other).Error() {
+		t.Errorf("expected combined message to match stacked message, got [%s]", err.Error())
+	}
+
+	tester.MustEquals(
+		t,
+		msa{"k": "v4", "k2": "v2", "k3": "v3"},
+		cluerr.CluesIn(err).Map(),
+		false)
+
+	tester.MustEquals(
+		t,
+		msa{"top": struct{}{}, "left": struct{}{}, "right": struct{}{}, "base": struct{}{}},
+		toMSA(cluerr.Labels(err)),
+		false)
+
+	msgs := []string{}
+	for _, c := range cluerr.Comments(err) {
+		msgs = append(msgs, c.Message)
+	}
+
+	assert.ElementsMatch(t, []string{"left comment", "right comment"}, msgs)
+
+	for _, e := range []error{left, right, other} {
+		if !errors.Is(err, e) {
+			t.Errorf("expected combined error to match [%v]", e)
+		}
+	}
+
+	if cluerr.Combine() != nil || cluerr.Combine(nil, nil) != nil {
+		t.Error("expected combining only nil errors to produce nil")
+	}
+}
+
+func TestChain(t *testing.T) {
+	var (
+		sentinel = errors.New("sentinel")
+		base     = cluerr.New("base").With("k", "v", "base", "b").Label("base")
+		next     = cluerr.Wrap(sentinel, "next").With("k", "v2", "next", "n").Label("next")
+	)
+
+	err := base.Chain(next)
+
+	assert.Equal(t, "base: next: sentinel", err.Error())
+	assert.ErrorIs(t, err, base)
+	assert.ErrorIs(t, err, next)
+	assert.ErrorIs(t, err, sentinel)
+
+	assert.Equal(
+		t,
+		map[string]any{"k": "v2", "base": "b", "next": "n"},
+		cluerr.CluesIn(err).Map())
+	assert.Equal(
+		t,
+		map[string]struct{}{"base": {}, "next": {}},
+		cluerr.Labels(err))
+
+	// chaining produces a new error, leaving the original untouched.
+	assert.NotErrorIs(t, base, sentinel)
+
+	// chains can continue fluently.
+	err = err.Chain(io.EOF)
+	assert.Equal(t, "base: next: sentinel: EOF", err.Error())
+	assert.ErrorIs(t, err, io.EOF)
+
+	assert.Same(t, base, base.Chain(nil), "chaining nil leaves the error unchanged")
+
+	var nilErr *cluerr.Err
+	assert.Nil(t, nilErr.Chain(nil))
+	assert.ErrorIs(t, nilErr.Chain(sentinel), sentinel)
+}
+
+type providerErr struct {
+	wrapped error
+}
+
+func (e providerErr) Error() string {
+	if e.wrapped == nil {
+		return "provider"
+	}
+
+	return "provider: " + e.wrapped.Error()
+}
+
+func (e providerErr) Unwrap() error {
+	return e.wrapped
+}
+
+func (e providerErr) Values() map[string]any {
+	return map[string]any{"pk": "pv", "k": "provider"}
+}
+
+func (e providerErr) Labels() map[string]struct{} {
+	return map[string]struct{}{"provider": {}}
+}
+
+func TestCluesProvider(t *testing.T) {
+	table := []struct {
+		name         string
+		err          error
+		expectValues map[string]any
+		expectLabels map[string]struct{}
+	}{
+		{
+			name:         "provider",
+			err:          providerErr{},
+			expectValues: map[string]any{"pk": "pv", "k": "provider"},
+			expectLabels: map[string]struct{}{"provider": {}},
+		},
+		{
+			name:         "wrapped provider",
+			err:          cluerr.Wrap(providerErr{}, "wrap").With("k", "v", "ek", "ev").Label("wrap"),
+			expectValues: map[string]any{"pk": "pv", "k": "provider", "ek": "ev"},
+			expectLabels: map[string]struct{}{"provider": {}, "wrap": {}},
+		},
+		{
+			name:         "provider wrapping clues",
+			err:          providerErr{wrapped: cluerr.New("base").With("k", "v", "bk", "bv").Label("base")},
+			expectValues: map[string]any{"pk": "pv", "k": "v", "bk": "bv"},
+			expectLabels: map[string]struct{}{"provider": {}, "base": {}},
+		},
+		{
+			name:         "stacked provider",
+			err:          cluerr.Stack(cluerr.New("top").Label("top"), fmt.Errorf("fmt: %w", providerErr{})),
+			expectValues: map[string]any{"pk": "pv", "k": "provider"},
+			expectLabels: map[string]struct{}{"provider": {}, "top": {}},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			core := cluerr.ToCore(test.err)
+			assert.Equal(t, test.expectValues, core.Values)
+			assert.Equal(t, test.expectLabels, core.Labels)
+
+			assert.Equal(t, test.expectValues, cluerr.CluesIn(test.err).Map())
+			assert.Equal(t, test.expectLabels, cluerr.Labels(test.err))
+			assert.True(t, cluerr.HasLabel(test.err, "provider"))
+		})
+	}
+}
+
 func TestImmutableErrors(t *testing.T) {
 	err := cluerr.New("an error").With("k", "v")
 	check := msa{"k": "v"}
@@ -1077,6 +3041,49 @@ func TestToCore(t *testing.T) {
 	}
 }
 
+func TestFlattenCores(t *testing.T) {
+	var (
+		sentinel = errors.New("sentinel")
+		inner    = cluerr.New("inner").With("ki", "vi").Label("li")
+		wrap     = cluerr.Wrap(cluerr.Stack(inner, sentinel), "wrap").
+				With("kw", "vw").
+				Label("lw").
+				Comment("a comment")
+		err = fmt.Errorf("fmt: %w", wrap)
+	)
+
+	type core struct {
+		msg    string
+		labels msa
+		values msa
+	}
+
+	expect := []core{
+		{"fmt: wrap: inner: sentinel", msa{}, msa{}},
+		{"", msa{}, msa{}},
+		{"wrap", msa{"lw": struct{}{}}, msa{"kw": "vw"}},
+		{"", msa{}, msa{}},
+		{"sentinel", msa{}, msa{}},
+		{"inner", msa{"li": struct{}{}}, msa{"ki": "vi"}},
+	}
+
+	cores := cluerr.FlattenCores(err)
+	require.Len(t, cores, len(expect))
+
+	for i, c := range cores {
+		assert.Equal(t, expect[i].msg, c.Msg, i)
+		assert.Equal(t, expect[i].labels, toMSA(c.Labels), i)
+		assert.Equal(t, expect[i].values, toMSA(c.Values), i)
+	}
+
+	// only the comment's node holds the comment.
+	require.Len(t, cores[1].Comments, 1)
+	assert.Equal(t, "a comment", cores[1].Comments[0].Message)
+	assert.Empty(t, cores[2].Comments)
+
+	assert.Empty(t, cluerr.FlattenCores(nil))
+}
+
 func TestStackNils(t *testing.T) {
 	result := cluerr.Stack(nil)
 	if result != nil {
@@ -1,7 +1,9 @@
 package cluerr
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/alcionai/clues/internal/node"
@@ -35,7 +37,7 @@ func (err *Err) Core() *ErrCore {
 	return &ErrCore{
 		Msg:      err.Error(),
 		Labels:   err.Labels(),
-		Values:   err.values(),
+		Values:   err.Values().Map(),
 		Comments: err.Comments(),
 	}
 }
@@ -59,6 +61,132 @@ func ToCore(err error) *ErrCore {
 	return e.Core()
 }
 
+// FlattenCores produces one ErrCore for each error in the tree, in the
+// same pre-order as WalkDepth.  Unlike ToCore, which aggregates the whole
+// tree into a single core, each core holds only the message, labels,
+// values, and comments of its own error.  Errors which aren't an *Err
+// produce a core holding their full error message, along with the labels
+// and values of any CluesProvider.
+func FlattenCores(err error) []*ErrCore {
+	cores := []*ErrCore{}
+
+	WalkDepth(err, func(_ int, en ErrNode) bool {
+		cores = append(cores, nodeCore(en))
+		return true
+	})
+
+	return cores
+}
+
+// nodeCore produces the ErrCore for a single error in the tree, without
+// aggregating the data of any wrapped or stacked errors.
+func nodeCore(en ErrNode) *ErrCore {
+	core := &ErrCore{
+		Labels:   sentinelLabels(en.Err),
+		Values:   map[string]any{},
+		Comments: node.CommentHistory{},
+	}
+
+	if core.Labels == nil {
+		core.Labels = map[string]struct{}{}
+	}
+
+	if ce := en.Clues; ce != nil {
+		core.Msg = ce.msg
+		maps.Copy(core.Labels, ce.labels)
+		maps.Copy(core.Values, ce.data.Map())
+		core.Comments = append(core.Comments, ce.data.Comments()...)
+
+		return core
+	}
+
+	core.Msg = en.Err.Error()
+
+	if cp, ok := en.Err.(CluesProvider); ok {
+		maps.Copy(core.Labels, cp.Labels())
+		maps.Copy(core.Values, cp.Values())
+	}
+
+	return core
+}
+
+// JSONString marshals the error's core into a json string, for quick
+// debugging dumps.  If pretty is true, the json is indented.  Values are
+// stringified, with concealed values remaining concealed.
+func (err *Err) JSONString(pretty bool) string {
+	if isNilErrIface(err) {
+		return "null"
+	}
+
+	core := err.Core()
+
+	vs := make(map[string]any, len(core.Values))
+	for k, v := range core.Values {
+		vs[k] = stringify.Marshal(v, true)
+	}
+
+	core.Values = vs
+
+	var (
+		bs   []byte
+		jerr error
+	)
+
+	if pretty {
+		bs, jerr = json.MarshalIndent(core, "", "  ")
+	} else {
+		bs, jerr = json.Marshal(core)
+	}
+
+	// with every value stringified, this shouldn't ever fail.
+	if jerr != nil {
+		return fmt.Sprintf("%q", core.String())
+	}
+
+	return string(bs)
+}
+
+// AsMap produces a single structured view of the error, suitable for
+// passing to arbitrary structured loggers.  The map contains:
+//
+//	message:  string, the full error message
+//	labels:   []string, the sorted labels
+//	values:   map[string]any, the error's values
+//	comments: []string, the message of each comment, oldest first
+//	trace:    []string, the caller and file of each error in the
+//	          chain, outermost first
+//	code:     string, the error's code, if one was set
+//
+// Values are not stringified, so concealed values remain concealed so
+// long as the logger formats them with fmt.
+func (err *Err) AsMap() map[string]any {
+	if isNilErrIface(err) {
+		return map[string]any{}
+	}
+
+	labels := maps.Keys(err.Labels())
+	slices.Sort(labels)
+
+	comments := []string{}
+	for _, c := range err.Comments() {
+		comments = append(comments, c.Message)
+	}
+
+	m := map[string]any{
+		"message":  err.Error(),
+		"labels":   labels,
+		"values":   err.Values().Map(),
+		"comments": comments,
+		"trace":    describeTrace(err),
+	}
+
+	if code := err.Code(); len(code) > 0 {
+		m["code"] = code
+	}
+
+	return m
+}
+
 func (ec *ErrCore) String() string {
 	if ec == nil {
 		return "<nil>"
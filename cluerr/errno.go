@@ -0,0 +1,47 @@
+package cluerr
+
+import (
+	"errors"
+	"syscall"
+)
+
+// ------------------------------------------------------------
+// errnos
+// ------------------------------------------------------------
+
+const (
+	// ErrnoKey is the value key under which WithErrno records the
+	// numeric errno.
+	ErrnoKey = "errno"
+	// ErrnoNameKey is the value key under which WithErrno records the
+	// name of the errno (ex: "ENOENT").
+	ErrnoNameKey = "errno_name"
+)
+
+// WithErrno records the errno of the provided error in the Err's data
+// map, if that error unwraps to a syscall.Errno.  The numeric errno gets
+// added under the "errno" key, and its name (ex: "ENOENT") under the
+// "errno_name" key.  This keeps the errno around for diagnosing
+// filesystem and network failures after the syscall error gets wrapped.
+// On platforms where errno names aren't known, only the number is added.
+// No-op if the error doesn't contain an errno.
+func (err *Err) WithErrno(sysErr error) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	var errno syscall.Errno
+	if !errors.As(sysErr, &errno) {
+		return err
+	}
+
+	vs := map[string]any{ErrnoKey: int(errno)}
+
+	if name := errnoName(errno); len(name) > 0 {
+		vs[ErrnoNameKey] = name
+	}
+
+	err.data = err.data.AddValues(vs)
+
+	return err
+}
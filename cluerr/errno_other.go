@@ -0,0 +1,11 @@
+//go:build !unix
+
+package cluerr
+
+import "syscall"
+
+// errnoName returns an empty string, since errno names aren't known on
+// this platform.
+func errnoName(syscall.Errno) string {
+	return ""
+}
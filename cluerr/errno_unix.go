@@ -0,0 +1,14 @@
+//go:build unix
+
+package cluerr
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// errnoName returns the name of the errno, such as "ENOENT".
+func errnoName(errno syscall.Errno) string {
+	return unix.ErrnoName(errno)
+}
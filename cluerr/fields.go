@@ -0,0 +1,78 @@
+package cluerr
+
+// ------------------------------------------------------------
+// field errors
+// ------------------------------------------------------------
+
+// FieldErrorsKey is the value key under which field errors are
+// stored in the error's data map.
+const FieldErrorsKey = "field_errors"
+
+// FieldErrors collects validation messages per field, such as when
+// validating a form or an api request body.  The zero value is ready
+// to use.
+type FieldErrors struct {
+	fields map[string][]string
+}
+
+// Add records the message against the field.  Fields may hold
+// multiple messages, which are retained in the order they were added.
+func (fe *FieldErrors) Add(field, msg string) *FieldErrors {
+	if fe.fields == nil {
+		fe.fields = map[string][]string{}
+	}
+
+	fe.fields[field] = append(fe.fields[field], msg)
+
+	return fe
+}
+
+// Len returns the number of fields with at least one message.
+func (fe *FieldErrors) Len() int {
+	if fe == nil {
+		return 0
+	}
+
+	return len(fe.fields)
+}
+
+// Map returns a copy of the field messages, keyed by field.
+func (fe *FieldErrors) Map() map[string][]string {
+	m := map[string][]string{}
+
+	if fe == nil {
+		return m
+	}
+
+	for k, v := range fe.fields {
+		m[k] = append([]string{}, v...)
+	}
+
+	return m
+}
+
+// WithFieldErrors adds a copy of the field errors to the Err's data
+// map under the "field_errors" key, as a map[string][]string.  Field
+// errors already present on this Err are merged with the new ones.
+// Empty field errors are ignored.
+func (err *Err) WithFieldErrors(fe *FieldErrors) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	if fe.Len() == 0 {
+		return err
+	}
+
+	merged := fe.Map()
+
+	if err.data != nil {
+		prior, _ := err.data.RawMap()[FieldErrorsKey].(map[string][]string)
+
+		for k, v := range prior {
+			merged[k] = append(append([]string{}, v...), merged[k]...)
+		}
+	}
+
+	return err.withValue(FieldErrorsKey, merged)
+}
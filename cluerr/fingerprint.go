@@ -0,0 +1,40 @@
+package cluerr
+
+import "slices"
+
+// ------------------------------------------------------------
+// fingerprints
+// ------------------------------------------------------------
+
+// WithFingerprint sets the parts which error-tracking systems should use
+// to group the error, in place of grouping by message or stack trace.
+// This gives callers deterministic control over grouping, such as
+// combining all "user not found" errors regardless of the user.  Calling
+// WithFingerprint again replaces the prior fingerprint.
+func (err *Err) WithFingerprint(parts ...string) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	err.fingerprint = slices.Clone(parts)
+
+	return err
+}
+
+// Fingerprint retrieves the fingerprint of the error.  Returns nil if no
+// fingerprint was set.
+func (err *Err) Fingerprint() []string {
+	return Fingerprint(err)
+}
+
+// Fingerprint retrieves the fingerprint of the error.  If multiple errors
+// in the tree have a fingerprint, the outermost fingerprint wins.  Returns
+// nil if no fingerprint was set.
+func Fingerprint(err error) []string {
+	ce := outermost(err, func(ce *Err) bool { return len(ce.fingerprint) > 0 })
+	if ce == nil {
+		return nil
+	}
+
+	return slices.Clone(ce.fingerprint)
+}
@@ -0,0 +1,132 @@
+// Package grpcstatus bridges clues errors to gRPC statuses.
+package grpcstatus
+
+import (
+	"slices"
+	"sync"
+
+	"golang.org/x/exp/maps"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/alcionai/clues/cluerr"
+	"github.com/alcionai/clues/internal/stringify"
+)
+
+// ------------------------------------------------------------
+// code mapping
+// ------------------------------------------------------------
+
+var (
+	mu sync.RWMutex
+
+	// mappings pairs error codes and labels with the gRPC code they
+	// translate to.
+	mappings = map[string]codes.Code{
+		"canceled":            codes.Canceled,
+		"unknown":             codes.Unknown,
+		"invalid_argument":    codes.InvalidArgument,
+		"deadline_exceeded":   codes.DeadlineExceeded,
+		"not_found":           codes.NotFound,
+		"already_exists":      codes.AlreadyExists,
+		"permission_denied":   codes.PermissionDenied,
+		"resource_exhausted":  codes.ResourceExhausted,
+		"failed_precondition": codes.FailedPrecondition,
+		"aborted":             codes.Aborted,
+		"out_of_range":        codes.OutOfRange,
+		"unimplemented":       codes.Unimplemented,
+		"internal":            codes.Internal,
+		"unavailable":         codes.Unavailable,
+		"data_loss":           codes.DataLoss,
+		"unauthenticated":     codes.Unauthenticated,
+	}
+)
+
+// Map registers the gRPC code that errors with the given clues code or
+// label translate to.  By default, the snake_case names of each gRPC code
+// (ex: "not_found", "invalid_argument") are mapped to their code.
+// Mapping an existing key replaces the prior code.
+func Map(codeOrLabel string, code codes.Code) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	mappings[codeOrLabel] = code
+}
+
+// codeFor finds the gRPC code for the error.  The error's clues code takes
+// priority over its labels.  Labels are checked in sorted order, so that
+// errors with multiple mapped labels produce a consistent result.
+func codeFor(err error, defaultCode codes.Code) codes.Code {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if c, ok := mappings[cluerr.Code(err)]; ok {
+		return c
+	}
+
+	labels := maps.Keys(cluerr.Labels(err))
+	slices.Sort(labels)
+
+	for _, l := range labels {
+		if c, ok := mappings[l]; ok {
+			return c
+		}
+	}
+
+	return defaultCode
+}
+
+// ------------------------------------------------------------
+// status
+// ------------------------------------------------------------
+
+// ToStatus converts the error into a gRPC status.  The status message is
+// the error message.  The status code is derived from the error's clues
+// code or labels (see Map), falling back to the defaultCode if neither
+// are mapped.  A nil error produces an OK status.
+//
+// If any detailKeys are provided, the error's values for those keys are
+// attached to the status as an ErrorInfo detail, with the error's clues
+// code as the reason.  Concealed values remain concealed.
+func ToStatus(
+	err error,
+	defaultCode codes.Code,
+	detailKeys ...string,
+) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	st := status.New(codeFor(err, defaultCode), err.Error())
+
+	if len(detailKeys) == 0 {
+		return st
+	}
+
+	var (
+		values = cluerr.CluesIn(err).Map()
+		md     = map[string]string{}
+	)
+
+	for _, k := range detailKeys {
+		if v, ok := values[k]; ok {
+			md[k] = stringify.Marshal(v, true)
+		}
+	}
+
+	if len(md) == 0 {
+		return st
+	}
+
+	detailed, derr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   cluerr.Code(err),
+		Metadata: md,
+	})
+	if derr != nil {
+		// details are best-effort; the code and message are still valid.
+		return st
+	}
+
+	return detailed
+}
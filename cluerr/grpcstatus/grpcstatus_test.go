@@ -0,0 +1,84 @@
+package grpcstatus_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+
+	"github.com/alcionai/clues/cecrets"
+	"github.com/alcionai/clues/cluerr"
+	"github.com/alcionai/clues/cluerr/grpcstatus"
+)
+
+func TestToStatus_code(t *testing.T) {
+	grpcstatus.Map("throttled", codes.ResourceExhausted)
+
+	table := []struct {
+		name   string
+		err    error
+		expect codes.Code
+	}{
+		{"nil", nil, codes.OK},
+		{"standard error", errors.New("err"), codes.Internal},
+		{"unmapped", cluerr.New("err").Label("foo").WithCode("bar"), codes.Internal},
+		{"mapped label", cluerr.New("err").Label("not_found"), codes.NotFound},
+		{"custom label", cluerr.New("err").Label("throttled"), codes.ResourceExhausted},
+		{"mapped code", cluerr.New("err").WithCode("permission_denied"), codes.PermissionDenied},
+		{
+			"code beats label",
+			cluerr.New("err").Label("not_found").WithCode("invalid_argument"),
+			codes.InvalidArgument,
+		},
+		{
+			"sorted labels",
+			cluerr.New("err").Label("unavailable", "aborted"),
+			codes.Aborted,
+		},
+		{
+			"wrapped",
+			fmt.Errorf("fmt: %w", cluerr.Wrap(cluerr.New("err").Label("not_found"), "wrap")),
+			codes.NotFound,
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			st := grpcstatus.ToStatus(test.err, codes.Internal)
+			assert.Equal(t, test.expect, st.Code())
+		})
+	}
+}
+
+func TestToStatus_message(t *testing.T) {
+	err := cluerr.Wrap(cluerr.New("base").Label("not_found"), "wrap")
+
+	st := grpcstatus.ToStatus(fmt.Errorf("fmt: %w", err), codes.Unknown)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "fmt: wrap: base", st.Message())
+	assert.Empty(t, st.Details())
+}
+
+func TestToStatus_details(t *testing.T) {
+	err := cluerr.New("err").
+		WithCode("not_found").
+		With(
+			"user_id", 1,
+			"secret", cecrets.Hide("shh"),
+			"other", "ignored")
+
+	st := grpcstatus.ToStatus(err, codes.Unknown, "user_id", "secret", "missing")
+	require.Len(t, st.Details(), 1)
+
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok, "detail is an ErrorInfo")
+
+	assert.Equal(t, "not_found", info.GetReason())
+	assert.Equal(t, "1", info.GetMetadata()["user_id"])
+	assert.NotEqual(t, "shh", info.GetMetadata()["secret"])
+	assert.NotContains(t, info.GetMetadata(), "other")
+	assert.NotContains(t, info.GetMetadata(), "missing")
+}
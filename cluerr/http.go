@@ -0,0 +1,55 @@
+package cluerr
+
+// ------------------------------------------------------------
+// http status
+// ------------------------------------------------------------
+
+const (
+	// LabelHTTP4xx is applied to errors with a 4xx http status.
+	LabelHTTP4xx = "http_4xx"
+	// LabelHTTP5xx is applied to errors with a 5xx http status.
+	LabelHTTP5xx = "http_5xx"
+	// LabelRetryable marks errors that are safe to retry.  Errors
+	// with a 5xx http status are automatically labeled as retryable.
+	LabelRetryable = "retryable"
+)
+
+// WithHTTPStatus sets the http status code on the error.  Statuses in the
+// 4xx range add the http_4xx label.  Statuses in the 5xx range add the
+// http_5xx label, and are also labeled as retryable.  Calling
+// WithHTTPStatus again replaces the prior status, but does not remove
+// any labels that were already applied.
+func (err *Err) WithHTTPStatus(code int) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	err.httpStatus = code
+
+	switch {
+	case code >= 400 && code < 500:
+		err = err.Label(LabelHTTP4xx)
+	case code >= 500 && code < 600:
+		err = err.Label(LabelHTTP5xx, LabelRetryable)
+	}
+
+	return err
+}
+
+// HTTPStatus retrieves the http status code of the error.  The bool is
+// false if no status was set.
+func (err *Err) HTTPStatus() (int, bool) {
+	return HTTPStatus(err)
+}
+
+// HTTPStatus retrieves the http status code of the error.  If multiple
+// errors in the tree have a status, the outermost status wins.  The bool
+// is false if no status was set.
+func HTTPStatus(err error) (int, bool) {
+	ce := outermost(err, func(ce *Err) bool { return ce.httpStatus != 0 })
+	if ce == nil {
+		return 0, false
+	}
+
+	return ce.httpStatus, true
+}
@@ -1,6 +1,11 @@
 package cluerr
 
-import "golang.org/x/exp/maps"
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
 
 // ------------------------------------------------------------
 // labels
@@ -11,6 +16,12 @@ func (err *Err) HasLabel(label string) bool {
 		return false
 	}
 
+	// labels on this error, including promoted labels, can be checked
+	// without walking the tree.
+	if _, ok := err.labels[label]; ok {
+		return true
+	}
+
 	// Check all labels in the error and it's stack since the stack isn't
 	// traversed separately. If we don't check the stacked labels here we'll skip
 	// checking them completely.
@@ -18,6 +29,10 @@ func (err *Err) HasLabel(label string) bool {
 		return true
 	}
 
+	if err.labelsStripped {
+		return false
+	}
+
 	return HasLabel(err.e, label)
 }
 
@@ -26,10 +41,20 @@ func HasLabel(err error, label string) bool {
 		return false
 	}
 
+	if _, ok := sentinelLabels(err)[label]; ok {
+		return true
+	}
+
 	if e, ok := err.(*Err); ok {
 		return e.HasLabel(label)
 	}
 
+	if cp, ok := err.(CluesProvider); ok {
+		if _, ok := cp.Labels()[label]; ok {
+			return true
+		}
+	}
+
 	return HasLabel(unwrap(err), label)
 }
 
@@ -43,6 +68,15 @@ func (err *Err) Label(labels ...string) *Err {
 	}
 
 	for _, label := range labels {
+		// only count labels that are new to this error.
+		if _, ok := err.labels[label]; ok {
+			continue
+		}
+
+		if err.data != nil && err.data.LabelCounter != nil {
+			err.data.LabelCounter.Add(label, 1)
+		}
+
 		err.labels[label] = struct{}{}
 	}
 
@@ -53,11 +87,92 @@ func Label(err error, label string) *Err {
 	return tryExtendErr(err, "", nil, 1).Label(label)
 }
 
+// LabelAll applies the labels to every *Err in the error tree, instead
+// of only the top-most error like Label does.  This allows downstream
+// label filters to match on any error in the tree, regardless of which
+// one they inspect.
+//
+// If the tree contains no *Err instances, the error is wrapped into a
+// new *Err which receives the labels.
+func LabelAll(err error, labels ...string) error {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	var found bool
+
+	for _, ancestor := range ancestors(err) {
+		ce, ok := ancestor.(*Err)
+		if !ok {
+			continue
+		}
+
+		ce.Label(labels...)
+
+		found = true
+	}
+
+	if !found {
+		return tryExtendErr(err, "", nil, 1).Label(labels...)
+	}
+
+	return err
+}
+
+// PromoteLabels collects the labels from every error in the tree and
+// applies them to the top-most error, so that repeated HasLabel checks
+// can be answered by the top-most error without walking the tree.  If
+// the top-most error isn't an *Err, it gets wrapped into a new *Err which
+// receives the labels.  Promoted labels don't count as newly added labels.
+func PromoteLabels(err error) error {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	var (
+		labels = Labels(err)
+		ce     = tryExtendErr(err, "", nil, 1)
+	)
+
+	if len(labels) == 0 {
+		return ce
+	}
+
+	if ce.labels == nil {
+		ce.labels = map[string]struct{}{}
+	}
+
+	maps.Copy(ce.labels, labels)
+
+	return ce
+}
+
+// StripLabels drops all labels from the error, including the labels of
+// any wrapped or stacked errors.  The errors in the tree are not modified;
+// instead, this error acts as a tombstone which hides their labels.  Labels
+// added to this error after stripping are retained as usual.
+//
+// This is useful when repurposing an error for a different subsystem.
+func (err *Err) StripLabels() *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	err.labels = map[string]struct{}{}
+	err.labelsStripped = true
+
+	return err
+}
+
 func (err *Err) Labels() map[string]struct{} {
 	if isNilErrIface(err) {
 		return map[string]struct{}{}
 	}
 
+	if err.labelsStripped {
+		return maps.Clone(err.labels)
+	}
+
 	labels := map[string]struct{}{}
 
 	for _, se := range err.stack {
@@ -73,15 +188,211 @@ func (err *Err) Labels() map[string]struct{} {
 	return labels
 }
 
+// Labels retrieves the labels from every error in the tree, including the
+// labels of any CluesProvider, and of any sentinel registered with
+// RegisterSentinel.
 func Labels(err error) map[string]struct{} {
+	labels := map[string]struct{}{}
+
 	for err != nil {
+		maps.Copy(labels, sentinelLabels(err))
+
 		e, ok := err.(*Err)
 		if ok {
-			return e.Labels()
+			maps.Copy(labels, e.Labels())
+			return labels
+		}
+
+		if cp, ok := err.(CluesProvider); ok {
+			maps.Copy(labels, cp.Labels())
 		}
 
 		err = unwrap(err)
 	}
 
-	return map[string]struct{}{}
+	return labels
+}
+
+// GroupByLabel buckets each leaf *Err in the error tree by its labels.
+// A leaf is any *Err that doesn't itself contain another *Err.  Leaves
+// with multiple labels appear in multiple buckets, while unlabeled leaves
+// are grouped under the empty string.
+//
+// This is handy for summarizing a batch of stacked failures by category.
+func GroupByLabel(err error) map[string][]error {
+	groups := map[string][]error{}
+
+	if isNilErrIface(err) {
+		return groups
+	}
+
+	walkLeaves(err, func(ce *Err) {
+		labels := ce.Labels()
+
+		if len(labels) == 0 {
+			groups[""] = append(groups[""], ce)
+			return
+		}
+
+		for label := range labels {
+			groups[label] = append(groups[label], ce)
+		}
+	})
+
+	return groups
+}
+
+// HasLabelPrefix returns true if any label in the error tree starts with
+// the prefix.  This allows handling a category of labels (ex: "db.")
+// without enumerating every label in the category.
+func (err *Err) HasLabelPrefix(prefix string) bool {
+	return HasLabelPrefix(err, prefix)
+}
+
+// HasLabelPrefix returns true if any label in the error tree starts with
+// the prefix.  This allows handling a category of labels (ex: "db.")
+// without enumerating every label in the category.
+func HasLabelPrefix(err error, prefix string) bool {
+	for label := range Labels(err) {
+		if strings.HasPrefix(label, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LabelsWithPrefix returns every label in the error tree that starts with
+// the prefix, sorted.
+func (err *Err) LabelsWithPrefix(prefix string) []string {
+	return LabelsWithPrefix(err, prefix)
+}
+
+// LabelsWithPrefix returns every label in the error tree that starts with
+// the prefix, sorted.
+func LabelsWithPrefix(err error, prefix string) []string {
+	result := []string{}
+
+	for label := range Labels(err) {
+		if strings.HasPrefix(label, prefix) {
+			result = append(result, label)
+		}
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+// FirstLabelWithPrefix returns the label with the prefix that was applied
+// to the outermost (most recently applied) error in the tree.  If one error
+// holds multiple matching labels, the lexically smallest is returned.  The
+// bool is false if no label matches.
+func (err *Err) FirstLabelWithPrefix(prefix string) (string, bool) {
+	return FirstLabelWithPrefix(err, prefix)
+}
+
+// FirstLabelWithPrefix returns the label with the prefix that was applied
+// to the outermost (most recently applied) error in the tree.  If one error
+// holds multiple matching labels, the lexically smallest is returned.  The
+// bool is false if no label matches.
+func FirstLabelWithPrefix(err error, prefix string) (string, bool) {
+	labels := orderedLabelsWithPrefix(err, prefix)
+	if len(labels) == 0 {
+		return "", false
+	}
+
+	return labels[0], true
+}
+
+// LastLabelWithPrefix returns the label with the prefix that was applied
+// to the innermost (least recently applied) error in the tree.  If one
+// error holds multiple matching labels, the lexically largest is returned.
+// The bool is false if no label matches.
+func (err *Err) LastLabelWithPrefix(prefix string) (string, bool) {
+	return LastLabelWithPrefix(err, prefix)
+}
+
+// LastLabelWithPrefix returns the label with the prefix that was applied
+// to the innermost (least recently applied) error in the tree.  If one
+// error holds multiple matching labels, the lexically largest is returned.
+// The bool is false if no label matches.
+func LastLabelWithPrefix(err error, prefix string) (string, bool) {
+	labels := orderedLabelsWithPrefix(err, prefix)
+	if len(labels) == 0 {
+		return "", false
+	}
+
+	return labels[len(labels)-1], true
+}
+
+// orderedLabelsWithPrefix lists every label with the prefix, ordered from
+// the outermost error in the tree to the innermost.  As with Labels, the
+// labels of registered sentinels and of any CluesProvider are included.
+// Labels hidden by StripLabels are excluded.
+func orderedLabelsWithPrefix(err error, prefix string) []string {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	var (
+		visible = Labels(err)
+		ancs    = ancestors(err)
+		result  = []string{}
+	)
+
+	for i := len(ancs) - 1; i >= 0; i-- {
+		var (
+			ancestor = ancs[i]
+			own      = sentinelLabels(ancestor)
+		)
+
+		if own == nil {
+			own = map[string]struct{}{}
+		}
+
+		if ce, ok := ancestor.(*Err); ok {
+			maps.Copy(own, ce.labels)
+		} else if cp, ok := ancestor.(CluesProvider); ok {
+			maps.Copy(own, cp.Labels())
+		}
+
+		labels := maps.Keys(own)
+		slices.Sort(labels)
+
+		for _, l := range labels {
+			if _, ok := visible[l]; ok && strings.HasPrefix(l, prefix) {
+				result = append(result, l)
+			}
+		}
+	}
+
+	return result
+}
+
+// walkLeaves calls fn with each leaf *Err in the tree, in ancestor order.
+// Leaf-ness is decided in the same pass, so the tree is only walked once.
+// Returns true if the tree contains any *Err.
+func walkLeaves(err error, fn func(*Err)) bool {
+	var hasErr bool
+
+	ce, ok := err.(*Err)
+
+	if ok {
+		for _, se := range ce.stack {
+			if !isNilErrIface(se) && walkLeaves(se, fn) {
+				hasErr = true
+			}
+		}
+	}
+
+	if unwrapped := unwrap(err); !isNilErrIface(unwrapped) && walkLeaves(unwrapped, fn) {
+		hasErr = true
+	}
+
+	if ok && !hasErr {
+		fn(ce)
+	}
+
+	return ok || hasErr
 }
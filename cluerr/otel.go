@@ -0,0 +1,96 @@
+package cluerr
+
+import (
+	"slices"
+
+	"go.opentelemetry.io/otel/codes"
+	otellog "go.opentelemetry.io/otel/log"
+	"golang.org/x/exp/maps"
+
+	"github.com/alcionai/clues/internal/node"
+)
+
+// LabelsAttrKey is the otel attribute key holding the error's labels.
+const LabelsAttrKey = "error_labels"
+
+// AsOTELRecord builds an otel log record out of the error.  The record
+// body contains the error message, and each of the error's values are
+// added as attributes.  The error's labels are added as a slice under
+// the LabelsAttrKey attribute.
+//
+// This allows log frontends other than clog to emit clues errors to otel.
+func AsOTELRecord(err error, severity otellog.Severity) otellog.Record {
+	record := otellog.Record{}
+	record.SetSeverity(severity)
+
+	if isNilErrIface(err) {
+		return record
+	}
+
+	record.SetBody(otellog.StringValue(err.Error()))
+
+	for k, v := range CluesIn(err).Map() {
+		record.AddAttributes(node.NewAttribute(k, v).KV())
+	}
+
+	if labels := Labels(err); len(labels) > 0 {
+		record.AddAttributes(LabelsAttr(labels))
+	}
+
+	return record
+}
+
+// LabelsAttr encodes the labels as the otel attribute used by
+// AsOTELRecord: a sorted slice of strings under the LabelsAttrKey.
+func LabelsAttr(labels map[string]struct{}) otellog.KeyValue {
+	ls := maps.Keys(labels)
+	slices.Sort(ls)
+
+	lvs := make([]otellog.Value, 0, len(ls))
+	for _, l := range ls {
+		lvs = append(lvs, otellog.StringValue(l))
+	}
+
+	return otellog.Slice(LabelsAttrKey, lvs...)
+}
+
+// AsOTELRecord builds an otel log record out of the error.  The record
+// body contains the error message, and each of the error's values are
+// added as attributes.  The error's labels are added as a slice under
+// the LabelsAttrKey attribute.
+func (err *Err) AsOTELRecord(severity otellog.Severity) otellog.Record {
+	return AsOTELRecord(err, severity)
+}
+
+// otelStatus is the span status preferred by an error.
+type otelStatus struct {
+	code codes.Code
+	desc string
+}
+
+// WithOTELStatus sets the status that gets reported on a span when the
+// error is recorded to it, such as by clues.SetSpanError.  This lets the
+// error dictate how it's reported, instead of the default codes.Error
+// status described by the error message.  Calling WithOTELStatus again
+// replaces the prior status.
+func (err *Err) WithOTELStatus(code codes.Code, desc string) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	err.otelStatus = &otelStatus{code: code, desc: desc}
+
+	return err
+}
+
+// OTELStatus retrieves the span status set on the error by
+// WithOTELStatus.  If multiple errors in the tree have a status, the
+// outermost status wins.  The bool is false if no status was set.
+func OTELStatus(err error) (codes.Code, string, bool) {
+	ce := outermost(err, func(ce *Err) bool { return ce.otelStatus != nil })
+	if ce == nil {
+		return codes.Unset, "", false
+	}
+
+	return ce.otelStatus.code, ce.otelStatus.desc, true
+}
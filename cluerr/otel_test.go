@@ -0,0 +1,68 @@
+package cluerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	otellog "go.opentelemetry.io/otel/log"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+func TestAsOTELRecord(t *testing.T) {
+	table := []struct {
+		name        string
+		err         error
+		expectBody  string
+		expectAttrs map[string]otellog.Value
+	}{
+		{
+			name:        "nil",
+			err:         nil,
+			expectAttrs: map[string]otellog.Value{},
+		},
+		{
+			name:        "standard error",
+			err:         errors.New("an error"),
+			expectBody:  "an error",
+			expectAttrs: map[string]otellog.Value{},
+		},
+		{
+			name: "clues error",
+			err: cluerr.Wrap(errors.New("an error"), "wrapped").
+				With("k", "v").
+				WithMap(map[string]any{"i": 1}).
+				Label("b", "a"),
+			expectBody: "wrapped: an error",
+			expectAttrs: map[string]otellog.Value{
+				"k": otellog.StringValue("v"),
+				"i": otellog.IntValue(1),
+				cluerr.LabelsAttrKey: otellog.SliceValue(
+					otellog.StringValue("a"),
+					otellog.StringValue("b")),
+			},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			record := cluerr.AsOTELRecord(test.err, otellog.SeverityError)
+
+			assert.Equal(t, otellog.SeverityError, record.Severity())
+			assert.Equal(t, test.expectBody, record.Body().AsString())
+
+			attrs := map[string]otellog.Value{}
+
+			record.WalkAttributes(func(kv otellog.KeyValue) bool {
+				attrs[kv.Key] = kv.Value
+				return true
+			})
+
+			assert.Len(t, attrs, len(test.expectAttrs))
+
+			for k, v := range test.expectAttrs {
+				assert.Truef(t, v.Equal(attrs[k]), "attribute %q: expected %v, got %v", k, v, attrs[k])
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Package problem bridges clues errors to RFC 7807 problem details.
+package problem
+
+import (
+	"encoding/json"
+
+	"github.com/alcionai/clues/cluerr"
+	"github.com/alcionai/clues/internal/stringify"
+)
+
+// ContentType is the media type of a problem details json document.
+const ContentType = "application/problem+json"
+
+// DefaultType is the problem type used when the error has no code.  Per
+// RFC 7807, it indicates that the problem has no semantics beyond those
+// of the http status.
+const DefaultType = "about:blank"
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	// Type identifies the problem type.  Populated from the error's code.
+	Type string `json:"type,omitempty"`
+	// Title is a short summary of the problem.  Populated from the
+	// outermost message in the error chain.
+	Title string `json:"title,omitempty"`
+	// Status is the http status code.  Populated from the error's http
+	// status.
+	Status int `json:"status,omitempty"`
+	// Detail is an explanation specific to this occurrence of the
+	// problem.  Populated from the full error message.
+	Detail string `json:"detail,omitempty"`
+	// Instance identifies this occurrence of the problem.  Left empty by
+	// FromError, for the caller to populate.
+	Instance string `json:"instance,omitempty"`
+	// Extensions holds any additional members.  Extensions never
+	// replace the standard members when marshaled.
+	Extensions map[string]any `json:"-"`
+}
+
+// FromError converts the error into a Problem.  The error's code becomes
+// the problem type (or DefaultType, if the error has no code), the
+// outermost message becomes the title, the full error message becomes the
+// detail, and the error's http status becomes the status.  A nil error
+// produces an empty Problem.
+//
+// If any extensionKeys are provided, the error's values for those keys
+// are added as extension members.  Only the listed keys are exposed, and
+// concealed values remain concealed.
+func FromError(err error, extensionKeys ...string) Problem {
+	if err == nil {
+		return Problem{}
+	}
+
+	p := Problem{
+		Type:   cluerr.Code(err),
+		Detail: err.Error(),
+	}
+
+	if len(p.Type) == 0 {
+		p.Type = DefaultType
+	}
+
+	for _, msg := range cluerr.MessageChain(err) {
+		if len(msg) > 0 {
+			p.Title = msg
+			break
+		}
+	}
+
+	if status, ok := cluerr.HTTPStatus(err); ok {
+		p.Status = status
+	}
+
+	if len(extensionKeys) == 0 {
+		return p
+	}
+
+	values := cluerr.CluesIn(err).Map()
+
+	for _, k := range extensionKeys {
+		v, ok := values[k]
+		if !ok {
+			continue
+		}
+
+		if p.Extensions == nil {
+			p.Extensions = map[string]any{}
+		}
+
+		p.Extensions[k] = stringify.Marshal(v, true)
+	}
+
+	return p
+}
+
+// MarshalJSON produces the problem details json document, with the
+// extension members flattened alongside the standard members.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type standard Problem
+
+	bs, err := json.Marshal(standard(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return bs, err
+	}
+
+	members := map[string]any{}
+
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+
+	// standard members take priority over extensions.
+	if err := json.Unmarshal(bs, &members); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(members)
+}
@@ -0,0 +1,95 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alcionai/clues/cecrets"
+	"github.com/alcionai/clues/cluerr"
+	"github.com/alcionai/clues/cluerr/problem"
+)
+
+func TestFromError(t *testing.T) {
+	table := []struct {
+		name   string
+		err    error
+		expect problem.Problem
+	}{
+		{
+			name:   "nil",
+			err:    nil,
+			expect: problem.Problem{},
+		},
+		{
+			name: "standard error",
+			err:  errors.New("err"),
+			expect: problem.Problem{
+				Type:   problem.DefaultType,
+				Title:  "err",
+				Detail: "err",
+			},
+		},
+		{
+			name: "coded",
+			err: cluerr.Wrap(cluerr.New("not found"), "getting user").
+				WithCode("user_not_found").
+				WithHTTPStatus(404),
+			expect: problem.Problem{
+				Type:   "user_not_found",
+				Title:  "getting user",
+				Status: 404,
+				Detail: "getting user: not found",
+			},
+		},
+		{
+			name: "wrapped",
+			err:  fmt.Errorf("%w", cluerr.New("base").WithCode("c").WithHTTPStatus(500)),
+			expect: problem.Problem{
+				Type:   "c",
+				Title:  "base",
+				Status: 500,
+				Detail: "base",
+			},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, problem.FromError(test.err))
+		})
+	}
+}
+
+func TestFromError_json(t *testing.T) {
+	err := cluerr.New("invalid email").
+		Label("validation").
+		WithCode("invalid_email").
+		WithHTTPStatus(400).
+		With(
+			"field", "email",
+			"secret", cecrets.Hide("hunter2"),
+			"internal", "not exposed",
+			"type", "not a replacement")
+
+	bs, jerr := json.Marshal(problem.FromError(err, "field", "secret", "type", "absent"))
+	require.NoError(t, jerr)
+
+	result := map[string]any{}
+	require.NoError(t, json.Unmarshal(bs, &result))
+
+	assert.Equal(
+		t,
+		map[string]any{
+			"type":   "invalid_email",
+			"title":  "invalid email",
+			"status": float64(400),
+			"detail": "invalid email",
+			"field":  "email",
+			"secret": cecrets.Hide("hunter2").Conceal(),
+		},
+		result)
+}
@@ -0,0 +1,229 @@
+package cluerr
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/alcionai/clues/cecrets"
+	"github.com/alcionai/clues/internal/node"
+	"github.com/alcionai/clues/internal/stringify"
+	"golang.org/x/exp/maps"
+)
+
+// ------------------------------------------------------------
+// redaction
+// ------------------------------------------------------------
+
+// Redacted produces a copy of the error where every value is concealed
+// using the currently configured cecrets hashing algorithm.  Messages,
+// labels, and the errors.Is/As chain of sentinel errors are preserved.
+// Comments are dropped, since they commonly embed runtime values.  The
+// values clues records on its own, such as the clues trace and agents,
+// are kept as-is so that the error can still be correlated.
+//
+// Use Redacted before returning an error across a trust boundary, such
+// as in an API response, to keep internal state from leaking.  The
+// original error is not modified.
+func (err *Err) Redacted() *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	return redact(err).(*Err)
+}
+
+// Sanitize produces a copy of the error which retains only the values
+// whose keys are in the allowKeys list.  All other values are removed.
+// Messages, labels, and the errors.Is/As chain of sentinel errors are
+// preserved.  Comments are dropped, same as with Redacted.
+//
+// Sanitize is stricter than Redacted: rather than concealing values, it
+// removes them entirely.  The values clues records on its own, such as
+// the clues trace and agents, are always retained.  The original error
+// is not modified.
+func (err *Err) Sanitize(allowKeys ...string) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	allow := make(map[string]struct{}, len(allowKeys))
+
+	for _, k := range allowKeys {
+		allow[k] = struct{}{}
+	}
+
+	return cloneValues(err, func(k string, v any) (string, any, bool) {
+		_, ok := allow[k]
+		return k, v, ok
+	}).(*Err)
+}
+
+// TransformValues produces a copy of the error where each value is
+// replaced by the result of fn, which receives every key and value and
+// returns the key and value to use in their place.  This allows keys to
+// be renamed and values to be reshaped in a single pass, such as to
+// normalize the error before exporting it.  It is the error-side
+// analogue of clues.SetValueTransformer.
+//
+// The values clues records on its own, such as the clues trace and
+// agents, are not passed to fn, and are kept as-is; fn can't rename a
+// value onto one of those keys.  If fn renames multiple keys to the same
+// key, only one of their values is kept.  Messages, labels, and the
+// errors.Is/As chain of sentinel errors are preserved.  Comments are
+// dropped, same as with Redacted.  The original error is not modified.
+func (err *Err) TransformValues(fn func(key string, v any) (string, any)) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	if fn == nil {
+		return cloneValues(err, func(k string, v any) (string, any, bool) {
+			return k, v, true
+		}).(*Err)
+	}
+
+	return cloneValues(err, func(k string, v any) (string, any, bool) {
+		nk, nv := fn(k, v)
+		return nk, nv, true
+	}).(*Err)
+}
+
+// Prune produces a copy of the error where any value whose stringified
+// size exceeds maxBytes is replaced with a `<pruned, N bytes>` marker,
+// where N is the size of the original value.  Use Prune to protect error
+// sinks from errors which accidentally captured large payloads.
+//
+// The values clues records on its own, such as the clues trace and
+// agents, are never pruned.  Messages, labels, and the errors.Is/As chain
+// of sentinel errors are preserved.  Comments are dropped, same as with
+// Redacted.  A maxBytes of zero or less disables pruning.  The original
+// error is not modified.
+func (err *Err) Prune(maxBytes int) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	if maxBytes <= 0 {
+		return err
+	}
+
+	return cloneValues(err, func(k string, v any) (string, any, bool) {
+		if n := len(stringify.Marshal(v, false)); n > maxBytes {
+			return k, fmt.Sprintf("<pruned, %d bytes>", n), true
+		}
+
+		return k, v, true
+	}).(*Err)
+}
+
+// redact produces a copy of the error with all values concealed.
+func redact(err error) error {
+	return cloneValues(err, func(k string, v any) (string, any, bool) {
+		return k, cecrets.Conceal(v), true
+	})
+}
+
+// cloneValues produces a copy of the error where each key and value is
+// replaced by the result of fn.  Values are dropped when fn returns false.
+// Values under the reserved keys (ex: the clues trace) are copied as-is,
+// without calling fn.  Non-clues errors are returned as-is, unless they
+// wrap a clues error, in which case they're replaced by a redactedErr that
+// retains the original message.
+func cloneValues(
+	err error,
+	fn func(k string, v any) (string, any, bool),
+) error {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	ce, ok := err.(*Err)
+	if !ok {
+		if !containsErr(err) {
+			return err
+		}
+
+		return &redactedErr{
+			msg: err.Error(),
+			e:   cloneValues(unwrap(err), fn),
+		}
+	}
+
+	var stack []error
+
+	for _, se := range ce.stack {
+		stack = append(stack, cloneValues(se, fn))
+	}
+
+	values := map[string]any{}
+
+	if ce.data != nil {
+		var (
+			raw      = ce.data.RawMap()
+			reserved = node.ReservedKeys()
+		)
+
+		for k, v := range raw {
+			if slices.Contains(reserved, k) {
+				continue
+			}
+
+			if nk, nv, ok := fn(k, v); ok {
+				values[nk] = nv
+			}
+		}
+
+		// reserved values are copied last so that fn can't replace them.
+		for _, k := range reserved {
+			if v, ok := raw[k]; ok {
+				values[k] = v
+			}
+		}
+	}
+
+	return &Err{
+		e:              cloneValues(ce.e, fn),
+		stack:          stack,
+		file:           ce.file,
+		caller:         ce.caller,
+		callerSkip:     ce.callerSkip,
+		msg:            ce.msg,
+		labels:         maps.Clone(ce.labels),
+		labelsStripped: ce.labelsStripped,
+		code:           ce.code,
+		fingerprint:    ce.fingerprint,
+		count:          ce.count,
+		httpStatus:     ce.httpStatus,
+		retryAfter:     ce.retryAfter,
+		otelStatus:     ce.otelStatus,
+		importedStack:  ce.importedStack,
+		data:           &node.Node{Values: values},
+	}
+}
+
+// containsErr returns true if any error in the tree is an *Err.
+func containsErr(err error) bool {
+	for _, ancestor := range ancestors(err) {
+		if _, ok := ancestor.(*Err); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactedErr stands in for a non-clues error which wraps a clues
+// error, since we can't otherwise copy the wrapper while replacing
+// the error it wraps.
+type redactedErr struct {
+	msg string
+	e   error
+}
+
+func (re *redactedErr) Error() string {
+	return re.msg
+}
+
+func (re *redactedErr) Unwrap() error {
+	return re.e
+}
@@ -0,0 +1,82 @@
+package cluerr
+
+// ------------------------------------------------------------
+// reduction
+// ------------------------------------------------------------
+
+// Reduce collapses consecutive no-op errors in a chain of wrapped errors
+// into a single error.  A no-op error is one that only wraps another error,
+// and holds no message, labels, values, comments, or other metadata, such
+// as the product of cluerr.Wrap(err, "").  Each collapsed run keeps the
+// trace of its outermost error.
+//
+// Reduction stops at the first error which stacks other errors.  The
+// original error is not modified.  If err is not an *Err, it gets wrapped
+// into one.
+func Reduce(err error) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	ce, ok := err.(*Err)
+	if !ok {
+		return newErr(err, "", nil, 1)
+	}
+
+	return reduce(ce)
+}
+
+// reduce produces a copy of the chain with consecutive no-op errors
+// collapsed.  Errors that don't change are returned as-is.
+func reduce(ce *Err) *Err {
+	if len(ce.stack) > 0 {
+		return ce
+	}
+
+	inner, ok := ce.e.(*Err)
+	if !ok || isNilErrIface(inner) {
+		return ce
+	}
+
+	reduced := reduce(inner)
+
+	if isNoopErr(ce) && isNoopErr(reduced) {
+		cp := *ce
+		cp.e = reduced.e
+
+		return &cp
+	}
+
+	if reduced != inner {
+		cp := *ce
+		cp.e = reduced
+
+		return &cp
+	}
+
+	return ce
+}
+
+// isNoopErr returns true if the error contributes nothing to the chain
+// other than its trace and the error it wraps.
+func isNoopErr(ce *Err) bool {
+	if len(ce.msg) > 0 ||
+		len(ce.stack) > 0 ||
+		len(ce.labels) > 0 ||
+		ce.labelsStripped ||
+		len(ce.code) > 0 ||
+		len(ce.fingerprint) > 0 ||
+		ce.count > 0 ||
+		ce.httpStatus != 0 ||
+		ce.retryAfter != nil ||
+		ce.otelStatus != nil ||
+		len(ce.importedStack) > 0 {
+		return false
+	}
+
+	if ce.data == nil {
+		return true
+	}
+
+	return len(ce.data.RawMap()) == 0 && len(ce.data.Comments()) == 0
+}
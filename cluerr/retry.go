@@ -0,0 +1,50 @@
+package cluerr
+
+import "time"
+
+// ------------------------------------------------------------
+// retries
+// ------------------------------------------------------------
+
+// WithRetryAfter records a suggested delay before retrying the operation
+// which produced the error, and labels the error as retryable.  Calling
+// WithRetryAfter again replaces the prior delay.  Negative durations are
+// treated as zero.
+func (err *Err) WithRetryAfter(d time.Duration) *Err {
+	if isNilErrIface(err) {
+		return nil
+	}
+
+	if d < 0 {
+		d = 0
+	}
+
+	err.retryAfter = &d
+
+	return err.Label(LabelRetryable)
+}
+
+// RetryAfter retrieves the suggested retry delay of the error.  The bool
+// is false if no delay was set.
+func (err *Err) RetryAfter() (time.Duration, bool) {
+	return RetryAfter(err)
+}
+
+// RetryAfter retrieves the suggested retry delay of the error.  If multiple
+// errors in the tree have a delay, the outermost delay wins.  The bool is
+// false if no delay was set.
+func RetryAfter(err error) (time.Duration, bool) {
+	ce := outermost(err, func(ce *Err) bool { return ce.retryAfter != nil })
+	if ce == nil {
+		return 0, false
+	}
+
+	return *ce.retryAfter, true
+}
+
+// IsRetryable returns true if any error in the tree is labeled as
+// retryable, such as by WithRetryAfter, or by WithHTTPStatus with a
+// 5xx status.
+func IsRetryable(err error) bool {
+	return HasLabel(err, LabelRetryable)
+}
@@ -0,0 +1,58 @@
+package cluerr
+
+import (
+	"reflect"
+	"sync"
+
+	"golang.org/x/exp/maps"
+)
+
+// ------------------------------------------------------------
+// sentinels
+// ------------------------------------------------------------
+
+var (
+	sentinelsMu sync.RWMutex
+
+	// sentinels pairs each registered sentinel error with the labels
+	// that errors containing it automatically receive.
+	sentinels = map[error]map[string]struct{}{}
+)
+
+// RegisterSentinel registers labels for the sentinel error.  Whenever the
+// sentinel appears in an error tree, such as in Stack(sentinel, err), the
+// labels get included by Labels and HasLabel without needing to label the
+// error at each call site.  Sentinels are matched by identity (==), not
+// by errors.Is.  Registering the same sentinel again adds to its labels.
+//
+// The sentinel must be comparable, as are the errors produced by
+// errors.New and New.  Non-comparable sentinels are ignored.
+func RegisterSentinel(sentinel error, labels ...string) {
+	if isNilErrIface(sentinel) || !reflect.TypeOf(sentinel).Comparable() {
+		return
+	}
+
+	sentinelsMu.Lock()
+	defer sentinelsMu.Unlock()
+
+	if sentinels[sentinel] == nil {
+		sentinels[sentinel] = map[string]struct{}{}
+	}
+
+	for _, label := range labels {
+		sentinels[sentinel][label] = struct{}{}
+	}
+}
+
+// sentinelLabels returns a copy of the labels registered for the error,
+// if the error is itself a registered sentinel.
+func sentinelLabels(err error) map[string]struct{} {
+	if isNilErrIface(err) || !reflect.TypeOf(err).Comparable() {
+		return nil
+	}
+
+	sentinelsMu.RLock()
+	defer sentinelsMu.RUnlock()
+
+	return maps.Clone(sentinels[err])
+}
@@ -2,11 +2,18 @@ package clues
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"time"
 
+	"github.com/alcionai/clues/cluerr"
 	"github.com/alcionai/clues/internal/node"
 	"github.com/alcionai/clues/internal/stringify"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/maps"
 )
 
 // ---------------------------------------------------------------------------
@@ -36,6 +43,8 @@ func InitializeOTEL(
 
 // Close will flush all buffered data waiting to be read.  If Initialize was not
 // called, this call is a no-op.  Should be called in a defer after initializing.
+// If using clog, call clog.Shutdown instead, which flushes buffered logs before
+// closing the otel client.
 func Close(ctx context.Context) error {
 	nc := node.FromCtx(ctx)
 
@@ -68,7 +77,7 @@ func Inherit(
 		// A span may already exist in the 'to' context thanks to otel package integration.
 		// Likewise, the 'from' ctx is not expected to contain a span, so we only want to
 		// maintain the span information that's currently live.
-		toNode.Span = trace.SpanFromContext(to)
+		toNode = toNode.WithSpan(trace.SpanFromContext(to))
 	}
 
 	// if we have no fromNode OTEL, or are not clobbering, return the toNode.
@@ -82,6 +91,323 @@ func Inherit(
 	return node.EmbedInCtx(to, toNode)
 }
 
+// Merge combines the clues from one or more contexts into a descendant
+// of the into ctx.  This is useful in a fan-in, where separate contexts
+// each hold clues that should appear together.
+//
+// Precedence on key collisions is, from highest to lowest:
+//  1. values already present in the into ctx.
+//  2. values in later from ctxs.
+//  3. values in earlier from ctxs.
+//
+// Only the values of the from ctxs are merged.  Their clues trace and
+// agents, along with the cancellation, deadline, and any other clients,
+// all come from the into ctx.
+func Merge(into context.Context, from ...context.Context) context.Context {
+	if into == nil {
+		into = context.Background()
+	}
+
+	var (
+		nc       = node.FromCtx(into)
+		existing = nc.RawMap()
+		merged   = map[string]any{}
+	)
+
+	for _, ctx := range from {
+		maps.Copy(merged, node.FromCtx(ctx).RawMap())
+	}
+
+	for _, k := range node.ReservedKeys() {
+		delete(merged, k)
+	}
+
+	for k := range existing {
+		delete(merged, k)
+	}
+
+	if len(merged) == 0 {
+		return into
+	}
+
+	return node.EmbedInCtx(into, nc.AddValues(merged))
+}
+
+// Isolate returns a ctx whose clues lineage is reset to a fresh root.
+// Values, comments, agents, and labels from the parent ctx do not carry
+// over to the isolated ctx, which keeps them from leaking into a logically
+// separate unit of work (such as processing untrusted nested data).
+//
+// The OTEL client and label counter are retained, and since the returned
+// ctx is derived from the parent, cancellation and deadlines still propagate.
+func Isolate(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	nc := node.FromCtx(ctx)
+
+	isolated := &node.Node{
+		OTEL:         nc.OTEL,
+		LabelCounter: nc.LabelCounter,
+	}
+
+	return node.EmbedInCtx(ctx, isolated)
+}
+
+// Detach returns a ctx which keeps all of the clues (including any label
+// counter) from the parent ctx, but is not canceled when the parent is.
+// This is useful for handing work off to a goroutine that outlives the
+// current request, while still counting the labels of any errors built
+// from the detached ctx with the original counter.
+func Detach(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	detached := context.WithoutCancel(ctx)
+
+	return node.EmbedInCtx(detached, node.FromCtx(ctx))
+}
+
+// Checkpoint records the clues in the ctx, and returns a restore func
+// which produces a ctx holding exactly the clues present at the time of
+// the checkpoint.  Anything added after the checkpoint (values, comments,
+// agents, spans, etc) is dropped from the restored ctx.  This is useful
+// for loops which add transient, per-iteration clues:
+//
+//	ctx, restore := clues.Checkpoint(ctx)
+//
+//	for _, item := range items {
+//		ctx = restore()
+//		ctx = clues.Add(ctx, "item_id", item.ID)
+//		...
+//	}
+//
+// The restored ctx is the checkpointed ctx itself, so any cancellation or
+// deadlines added after the checkpoint are dropped as well.
+func Checkpoint(ctx context.Context) (context.Context, func() context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	checkpoint := node.EmbedInCtx(ctx, node.FromCtx(ctx))
+
+	return checkpoint, func() context.Context {
+		return checkpoint
+	}
+}
+
+// OnCtxDone runs fn in its own goroutine once the ctx is done (canceled, or
+// past its deadline).  fn receives the cancellation cause, as reported by
+// context.Cause(ctx), so that it can record why the work stopped; for
+// example, by logging the cause along with the clues in the ctx.
+//
+// If the ctx can never be done (ex: context.Background()), fn never runs.
+func OnCtxDone(ctx context.Context, fn func(cause error)) {
+	if ctx == nil || fn == nil {
+		return
+	}
+
+	context.AfterFunc(ctx, func() {
+		fn(context.Cause(ctx))
+	})
+}
+
+// ---------------------------------------------------------------------------
+// configuration
+// ---------------------------------------------------------------------------
+
+// SetRejectEmptyKeys toggles whether key:value pairs with an empty string key
+// get dropped.  When enabled, Add, AddMap, and their error counterparts (With,
+// WithMap, etc) silently discard any pair whose key is "".  Defaults to false.
+func SetRejectEmptyKeys(reject bool) {
+	node.SetRejectEmptyKeys(reject)
+}
+
+// SetValueTransformer sets a func that reshapes values (ex: truncating,
+// hashing, or normalizing them) at the time the clues are flattened, such
+// as when they're logged or read with clues.In(ctx).Map().  The stored
+// values are left pristine; only the emitted values are transformed.  The
+// transformer is called once per key, and its result replaces the value.
+//
+// Passing a nil func disables transformation, which is the default.
+func SetValueTransformer(fn func(key string, value any) any) {
+	node.SetValueTransformer(fn)
+}
+
+// OddArgsPolicy determines how a dangling key, one without a paired value,
+// gets handled when clues are given an odd number of key-value args.
+type OddArgsPolicy = stringify.OddArgsPolicy
+
+const (
+	// OddArgsNil pairs the dangling key with a nil value.  This is the
+	// default policy.
+	OddArgsNil = stringify.OddArgsNil
+	// OddArgsDrop discards the dangling key.
+	OddArgsDrop = stringify.OddArgsDrop
+	// OddArgsMissing pairs the dangling key with the value "<missing>".
+	OddArgsMissing = stringify.OddArgsMissing
+)
+
+// SetOddArgsPolicy sets how a dangling key gets handled when an odd number
+// of key-value args are provided, such as in clues.Add(ctx, "k1", v1, "k2").
+// The policy applies consistently across clues, cluerr's With, and clog's
+// With.  Defaults to OddArgsNil.
+func SetOddArgsPolicy(policy OddArgsPolicy) {
+	stringify.SetOddArgsPolicy(policy)
+}
+
+// FlattenCores produces one cluerr.ErrCore for each error in the tree,
+// in pre-order, where each core holds only its own error's message,
+// labels, values, and comments.  Use cluerr.ToCore instead to aggregate
+// the whole tree into a single core.
+func FlattenCores(err error) []*cluerr.ErrCore {
+	return cluerr.FlattenCores(err)
+}
+
+// PromoteLabels collects the labels from every error in the tree and
+// applies them to the top-most error, so that repeated cluerr.HasLabel
+// checks don't need to walk the tree.  See cluerr.PromoteLabels.
+func PromoteLabels(err error) error {
+	return cluerr.PromoteLabels(err)
+}
+
+// RegisterSentinel registers labels for the sentinel error.  Whenever the
+// sentinel appears in an error tree, such as in cluerr.Stack(sentinel, err),
+// cluerr.Labels and cluerr.HasLabel include its labels automatically, so
+// that each call site needn't label the error.  Sentinels are matched by
+// identity.
+func RegisterSentinel(sentinel error, labels ...string) {
+	cluerr.RegisterSentinel(sentinel, labels...)
+}
+
+// SetTimeFormat sets the layout used to render time.Time values whenever
+// they get stringified, such as when added to the clues with Add, or when
+// recorded in logs and span attributes.  Defaults to time.RFC3339Nano.
+// An empty layout restores the default.
+func SetTimeFormat(layout string) {
+	stringify.SetTimeFormat(layout)
+}
+
+// OnAdd registers a hook which gets called with every key and value
+// added to the clues, whether in a ctx (ex: Add, AddMap) or in an error
+// (ex: cluerr's With, WithMap), so that additions can be audited or
+// validated.  The hook receives the key and value after normalization.
+// Only one hook can be registered at a time; passing a nil func removes
+// the hook, which is the default.
+//
+// The hook is called synchronously on every addition, so it should be
+// quick, and safe for concurrent use.
+func OnAdd(fn func(key string, value any)) {
+	node.SetOnAdd(fn)
+}
+
+// Frame describes a single caller in the call stack.
+type Frame = node.Frame
+
+// SetTraceProvider replaces the func that clues uses to capture the
+// caller info (func, file, and line) for errors and comments.  The
+// provider receives a skip-caller depth, where 0 is the func that
+// called the provider, similar to runtime.Caller.
+//
+// The default provider uses runtime.Caller.  Performance-sensitive
+// users can supply an alternative, such as one backed by a frame
+// cache, for hot paths.  Passing a nil provider restores the default.
+func SetTraceProvider(provider func(depth int) Frame) {
+	node.SetTraceProvider(provider)
+}
+
+// SetStrictMode toggles a debugging mode that helps catch a common bug:
+// calling an adder like clues.Add(ctx, ...) and dropping the returned ctx.
+// While enabled, each ctx produced by clues is tracked, and a warning is
+// printed to stderr if it gets garbage collected without its clues ever
+// being read.
+//
+// Strict mode adds overhead to every clues addition, and can report false
+// positives for ctxs which are legitimately never read.  It is intended for
+// use in tests and local debugging, not in production.
+func SetStrictMode(enabled bool) {
+	node.SetStrictMode(enabled, nil)
+}
+
+// Comment is a single comment added to the clues.
+type Comment = node.Comment
+
+// SetCommentFormatter replaces the func that renders each comment when a
+// comment history is stringified, such as by clues.In(ctx).Comments().String().
+// Comments are rendered in order, one per line.  This allows downstream
+// tools to produce alternative layouts, like JSON lines.
+//
+// The default format is "<caller> - <file>:<line>\n\t<message>".  Passing
+// a nil func restores the default.
+func SetCommentFormatter(fn func(Comment) string) {
+	node.SetCommentFormatter(fn)
+}
+
+// SetNodeIDFunc replaces the func that generates the IDs which appear
+// in the clues_trace value.  By default, IDs are random 8 character
+// hashes, which makes clues_trace values impossible to predict.  Tests
+// can install a deterministic generator, such as a counter, to assert
+// on the trace.  Passing a nil func restores the default.
+func SetNodeIDFunc(fn func() string) {
+	node.SetNodeIDFunc(fn)
+}
+
+// TraceKey is the default key under which the IDs of the clues nodes are
+// recorded when the clues are flattened, such as by In(ctx).Map().  See
+// SetTraceKey to change it.
+const TraceKey = node.DefaultTraceKey
+
+// ExtractTrace retrieves the clues node IDs from a map produced by
+// In(ctx).Map(), splitting the trace value by the separator.  Respects the
+// key and separator set by SetTraceKey and SetTraceSeparator.  Returns nil
+// if the map holds no trace.
+func ExtractTrace(m map[string]any) []string {
+	return node.ExtractTrace(m)
+}
+
+// SetTraceKey renames the key under which the IDs of the clues nodes are
+// recorded when the clues are flattened, such as by In(ctx).Map().  This
+// helps avoid collisions with other keys.  An empty key restores the
+// default, "clues_trace".
+func SetTraceKey(key string) {
+	node.SetTraceKey(key)
+}
+
+// SetTraceSeparator changes the separator used to join the node IDs in
+// the trace value, for compatibility with log parsers.  An empty separator
+// restores the default, ",".
+func SetTraceSeparator(sep string) {
+	node.SetTraceSeparator(sep)
+}
+
+// SetSpanAttrKeyLimit caps the number of distinct attribute keys that clues
+// will add to a single span.  Once a span holds n keys, any new keys are
+// dropped and the span is marked with attrs_truncated=true instead.  Keys
+// already on the span can still be updated.  Values of n <= 0 remove the
+// limit, which is the default.
+func SetSpanAttrKeyLimit(n int) {
+	node.SetSpanAttrKeyLimit(n)
+}
+
+// SetSpanAttrAllowlist restricts the clues values which get added to the
+// current span to those with a key in the allowlist.  All values remain
+// available in the ctx for logging and errors; only span attributes get
+// filtered.  This keeps span cardinality in check without limiting what
+// the clues hold.  Calling SetSpanAttrAllowlist with no keys removes the
+// allowlist.
+func SetSpanAttrAllowlist(keys ...string) {
+	node.SetSpanAttrAllowlist(keys...)
+}
+
+// SetBaggageByteLimit sets the maximum serialized size, in bytes, of the
+// baggage that AddBaggage and AddBaggageProps will produce.  Values of
+// n <= 0 restore the default of 8192 bytes, as per the W3C spec.
+func SetBaggageByteLimit(n int) {
+	node.SetBaggageByteLimit(n)
+}
+
 // ---------------------------------------------------------------------------
 // data access
 // ---------------------------------------------------------------------------
@@ -116,6 +442,162 @@ func AddMap[K comparable, V any](
 	return node.EmbedInCtx(ctx, nc.AddValues(stringify.Normalize(kvs...)))
 }
 
+// AddStatic binds values, such as the service version, hostname, or
+// region, which should appear alongside every other clue.  It is meant
+// to be called once at startup, on the root ctx.
+//
+// Static values always take the lowest priority: any value added with
+// Add, AddMap, or similar funcs under the same key overrides the static
+// value, regardless of which was added first.  Like other clues, static
+// values are included in errors built from the ctx.  Unlike other clues,
+// they are not added to the current span.
+func AddStatic(ctx context.Context, m map[string]any) context.Context {
+	nc := node.FromCtx(ctx)
+	return node.EmbedInCtx(ctx, nc.AddStaticValues(m))
+}
+
+// AddLazy adds a value to the clues which is only computed when the clues
+// are flattened, such as when they're logged or added to an error.  This
+// is useful for values that are expensive to produce, and aren't needed
+// on the happy path.  The func is called at most once, and its result is
+// cached for every later read.  If an OnAdd hook is registered, the func
+// gets called right away so that the hook can receive the value.
+//
+// Lazy values are not added to the current span.
+func AddLazy(
+	ctx context.Context,
+	key string,
+	fn func() any,
+) context.Context {
+	nc := node.FromCtx(ctx)
+	return node.EmbedInCtx(ctx, nc.AddLazy(key, fn))
+}
+
+// StartTimer adds the current time to the clues under the key, marking
+// the start of an operation.  The time retains its type when read back
+// out of the clues.  Errors can record the time elapsed since the start
+// with cluerr's SinceCtx:
+//
+//	ctx = clues.StartTimer(ctx, "upload_start")
+//	...
+//	return cluerr.WrapWC(ctx, err, "uploading").SinceCtx(ctx, "upload_start")
+func StartTimer(ctx context.Context, key string) context.Context {
+	nc := node.FromCtx(ctx)
+	return node.EmbedInCtx(ctx, nc.AddValues(map[string]any{key: time.Now()}))
+}
+
+// AddIf adds the key-value pair to the clues only if cond is true.
+// If cond is false, the ctx is returned unchanged.
+func AddIf(
+	ctx context.Context,
+	cond bool,
+	key string,
+	value any,
+) context.Context {
+	if !cond {
+		return ctx
+	}
+
+	return Add(ctx, key, value)
+}
+
+// AddIfNotZero adds the key-value pair to the clues only if the value
+// is not the zero value for its type (ex: nil, "", 0, false, or an
+// empty struct).  Otherwise the ctx is returned unchanged.
+func AddIfNotZero(
+	ctx context.Context,
+	key string,
+	value any,
+) context.Context {
+	if value == nil || reflect.ValueOf(value).IsZero() {
+		return ctx
+	}
+
+	return Add(ctx, key, value)
+}
+
+// ---------------------------------------------------------------------------
+// label counting
+// ---------------------------------------------------------------------------
+
+// Adder is the interface used to count labels.  Each time a new label
+// is added to an error holding a counter, Add(label, 1) gets called.
+type Adder interface {
+	Add(key string, n int64)
+}
+
+// AddLabelCounter embeds the counter in the clues.  Errors that pull in
+// the clues from this ctx (ex: clues.NewWC(ctx, msg), or err.WithClues(ctx))
+// will count every unique label added to them using the counter.
+//
+// Any label counter already in the ctx, including the counters set by
+// BindLabelCounterToMeter and WithLabelThreshold, continues to receive
+// all counts.
+func AddLabelCounter(ctx context.Context, counter Adder) context.Context {
+	nc := node.FromCtx(ctx)
+	return node.EmbedInCtx(ctx, nc.AddLabelCounter(counter))
+}
+
+// BindLabelCounterToMeter embeds a label counter in the clues which
+// increments the otel Int64 counter "clues.labels" each time a label is
+// counted, with the label recorded as the "label" attribute.  This bridges
+// in-process label counting to real metrics.
+//
+// Any label counter already in the ctx continues to receive all counts.
+// If otel has not been initialized, the ctx is returned unchanged.
+func BindLabelCounterToMeter(ctx context.Context) context.Context {
+	nc := node.FromCtx(ctx)
+
+	spawn, err := nc.BindLabelCounterToMeter()
+	if err != nil {
+		return ctx
+	}
+
+	return node.EmbedInCtx(ctx, spawn)
+}
+
+// WithLabelThreshold sets a threshold of n for the label.  Once errors
+// built from this ctx have counted the label more than n times,
+// LabelExceeded(ctx, label) will return true.  This allows for simple,
+// in-process circuit breaking on categories of errors.
+//
+// Any label counter already in the ctx continues to receive all counts.
+func WithLabelThreshold(
+	ctx context.Context,
+	label string,
+	n int64,
+) context.Context {
+	nc := node.FromCtx(ctx)
+	return node.EmbedInCtx(ctx, nc.WithLabelThreshold(label, n))
+}
+
+// LabelExceeded returns true if errors built from this ctx have counted
+// the label more times than the threshold set by WithLabelThreshold.
+// Returns false if no threshold was set for the label.
+func LabelExceeded(ctx context.Context, label string) bool {
+	return node.FromCtx(ctx).LabelExceeded(label)
+}
+
+// ---------------------------------------------------------------------------
+// sampling
+// ---------------------------------------------------------------------------
+
+// SetSampled records a single, per-request sampling decision in the ctx.
+// That decision is shared by trace sampling (for spans started with
+// AddSpan) and by clog's debug logging, so that log verbosity and trace
+// sampling don't diverge within a request.
+func SetSampled(ctx context.Context, sampled bool) context.Context {
+	nc := node.FromCtx(ctx)
+	return node.EmbedInCtx(ctx, nc.SetSampled(sampled))
+}
+
+// IsSampled returns the sampling decision recorded by SetSampled.
+// Returns false if no decision was recorded.
+func IsSampled(ctx context.Context) bool {
+	sampled, _ := node.FromCtx(ctx).IsSampled()
+	return sampled
+}
+
 // ---------------------------------------------------------------------------
 // spans and traces
 // ---------------------------------------------------------------------------
@@ -154,27 +636,77 @@ func ReceiveTrace[C node.TraceMapCarrierBase](
 // marker.  The assumption is that an otel span is generated and attached
 // to the node.  Callers should always follow this addition with a closing
 // `defer clues.CloseSpan(ctx)`.
+//
+// The spanned ctx carries forward the parent's clues, including any label
+// counter, so errors built from it continue to count their labels.
 func AddSpan(
 	ctx context.Context,
 	name string,
 	kvs ...any,
+) context.Context {
+	return addSpan(ctx, name, nil, kvs...)
+}
+
+// addSpan stacks a clues node with a new span onto the context, starting
+// the span with the provided options.
+func addSpan(
+	ctx context.Context,
+	name string,
+	opts []trace.SpanStartOption,
+	kvs ...any,
 ) context.Context {
 	nc := node.FromCtx(ctx)
 
 	var spanned *node.Node
 
 	if len(kvs) > 0 {
-		ctx, spanned = nc.AddSpan(ctx, name)
+		ctx, spanned = nc.AddSpan(ctx, name, opts...)
 		spanned.ID = name
 		spanned = spanned.AddValues(stringify.Normalize(kvs...))
 	} else {
-		ctx, spanned = nc.AddSpan(ctx, name)
+		ctx, spanned = nc.AddSpan(ctx, name, opts...)
 		spanned = spanned.AppendToTree(name)
 	}
 
 	return node.EmbedInCtx(ctx, spanned)
 }
 
+// spanBuilder configures a span before it gets started.
+type spanBuilder struct {
+	opts []trace.SpanStartOption
+}
+
+// NewSpan produces a builder for spans which need more configuration
+// than AddSpan provides.  Call Start on the builder to add the span
+// to the ctx.
+//
+//	ctx = clues.NewSpan().
+//		WithStartTime(job.EnqueuedAt).
+//		Start(ctx, "process-job")
+//	defer clues.CloseSpan(ctx)
+func NewSpan() *spanBuilder {
+	return &spanBuilder{}
+}
+
+// WithStartTime sets the start time of the span.  This allows spans to be
+// created for operations whose start is only learned retroactively, such
+// as a queued job's enqueue time.
+func (sb *spanBuilder) WithStartTime(t time.Time) *spanBuilder {
+	sb.opts = append(sb.opts, trace.WithTimestamp(t))
+	return sb
+}
+
+// Start adds the configured span to the ctx.  It behaves the same as
+// AddSpan, and should always be followed by a closing
+// `defer clues.CloseSpan(ctx)`.
+func (sb *spanBuilder) Start(
+	ctx context.Context,
+	name string,
+	kvs ...any,
+) context.Context {
+	return addSpan(ctx, name, sb.opts, kvs...)
+}
+
 // CloseSpan closes the current span in the clues node.  Should only be called
 // following a `clues.AddSpan()` call.
 func CloseSpan(ctx context.Context) context.Context {
@@ -183,6 +715,118 @@ func CloseSpan(ctx context.Context) context.Context {
 		node.FromCtx(ctx).CloseSpan(ctx))
 }
 
+// EndSpanReturnParent closes the current span in the clues node, and
+// returns a ctx in which the parent span is active again.  Any values
+// added to the returned ctx, or spans started from it, attach to the
+// parent span.  If the current span has no parent, the returned ctx
+// has no active span.  Should only be called following a
+// `clues.AddSpan()` call.
+func EndSpanReturnParent(ctx context.Context) context.Context {
+	ctx, parent := node.FromCtx(ctx).CloseSpanReturnParent(ctx)
+	return node.EmbedInCtx(ctx, parent)
+}
+
+// SetSpanError records the error on the current span, and sets the span's
+// status.  By default the status is codes.Error, described by the error
+// message.  If the error carries a preferred status (see
+// cluerr.WithOTELStatus), that status is used instead.  No-ops if the
+// error is nil or the ctx has no span.
+func SetSpanError(ctx context.Context, err error) {
+	span := node.FromCtx(ctx).Span
+	if err == nil || span == nil {
+		return
+	}
+
+	span.RecordError(err)
+
+	code, desc, ok := cluerr.OTELStatus(err)
+	if !ok {
+		code, desc = codes.Error, err.Error()
+	}
+
+	span.SetStatus(code, desc)
+}
+
+// EndSpanWithError records the error on the current span, as with
+// SetSpanError, and then closes the span, as with CloseSpan.
+func EndSpanWithError(ctx context.Context, err error) context.Context {
+	SetSpanError(ctx, err)
+	return CloseSpan(ctx)
+}
+
+// BaggageLimitLabel is applied to errors produced when an addition would
+// push the ctx's baggage beyond the baggage byte limit.
+const BaggageLimitLabel = "baggage_limit"
+
+// AddBaggage adds the key:value pair to the otel baggage in the ctx.
+// Baggage gets propagated to downstream services alongside the trace.
+//
+// OTEL and the W3C spec cap the serialized size of baggage.  If this
+// addition would exceed the limit (see SetBaggageByteLimit), the ctx is
+// returned unchanged along with an error labeled BaggageLimitLabel.
+func AddBaggage(
+	ctx context.Context,
+	k, v string,
+) (context.Context, error) {
+	return addBaggage(ctx, k, v, nil)
+}
+
+// AddBaggageProps is the same as AddBaggage, but also attaches the
+// properties to the baggage member.
+func AddBaggageProps(
+	ctx context.Context,
+	k, v string,
+	props map[string]string,
+) (context.Context, error) {
+	return addBaggage(ctx, k, v, props)
+}
+
+func addBaggage(
+	ctx context.Context,
+	k, v string,
+	props map[string]string,
+) (context.Context, error) {
+	bagged, err := node.AddBaggage(ctx, k, v, props)
+	if err == nil {
+		return bagged, nil
+	}
+
+	cerr := cluerr.WrapWC(ctx, err, "adding baggage").
+		With("baggage_key", k).
+		SkipCaller(2)
+
+	if errors.Is(err, node.ErrBaggageLimit) {
+		cerr = cerr.Label(BaggageLimitLabel)
+	}
+
+	return ctx, cerr
+}
+
+// CorrelationIDKey is the key which holds the correlation ID, both in the
+// clues values and in the baggage.
+const CorrelationIDKey = "correlation_id"
+
+// WithCorrelationID sets the correlation ID in the ctx, unifying a single
+// identifier across logs, traces, and errors.  The ID is added to the clues
+// values (and therefore to any errors built from the ctx, such as with
+// cluerr.NewWC), and to the baggage for propagation to downstream services.
+// If the id is empty, a new one is generated.
+//
+// If the ID can't be added to the baggage, the returned ctx still holds
+// the ID in its clues values, alongside the baggage error.
+func WithCorrelationID(
+	ctx context.Context,
+	id string,
+) (context.Context, error) {
+	if len(id) == 0 {
+		id = uuid.NewString()
+	}
+
+	ctx = Add(ctx, CorrelationIDKey, id)
+
+	return addBaggage(ctx, CorrelationIDKey, id, nil)
+}
+
 // ---------------------------------------------------------------------------
 // comments
 // ---------------------------------------------------------------------------
@@ -219,6 +863,44 @@ func AddComment(
 	return node.EmbedInCtx(ctx, nn)
 }
 
+// ---------------------------------------------------------------------------
+// scopes
+// ---------------------------------------------------------------------------
+
+// Scope tags the ctx, and every ctx derived from it, as belonging to the
+// scope.  Data added within a scope (values, static values, comments,
+// ops, and clog default fields) is hidden in every other scope, including
+// from In(ctx) and from errors built with the ctx.  That way a ctx which
+// gets incorrectly reused across scopes (ex: across tenants in a
+// multi-tenant worker) can't leak data between them.  Data added before
+// any scope was set remains visible in every scope.  Empty scope IDs are
+// ignored.
+func Scope(ctx context.Context, scopeID string) context.Context {
+	nc := node.FromCtx(ctx)
+	return node.EmbedInCtx(ctx, nc.AddScope(scopeID))
+}
+
+// ---------------------------------------------------------------------------
+// operations
+// ---------------------------------------------------------------------------
+
+// PushOp pushes the op onto the ctx's operation stack.  Each op gets
+// recorded in its own node, so that the stack grows as the ctx passes
+// down through the call chain, and descendants never affect the stack
+// of their ancestors.  The stack appears in the clues under the
+// "clues_ops" key, joined with ">" (ex: "handler>service>repo"), so that
+// logs can show the current operation path.  Empty ops are ignored.
+func PushOp(ctx context.Context, op string) context.Context {
+	nc := node.FromCtx(ctx)
+	return node.EmbedInCtx(ctx, nc.AddOp(op))
+}
+
+// Ops returns the ctx's operation stack, from the first op pushed to
+// the most recent.
+func Ops(ctx context.Context) []string {
+	return node.FromCtx(ctx).Ops()
+}
+
 // ---------------------------------------------------------------------------
 // agents
 // ---------------------------------------------------------------------------
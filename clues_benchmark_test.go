@@ -3,6 +3,7 @@ package clues_test
 import (
 	"context"
 	"math/rand"
+	"strconv"
 	"testing"
 
 	"github.com/alcionai/clues"
@@ -197,6 +198,22 @@ func BenchmarkAddMap_randKRandV(b *testing.B) {
 	}
 }
 
+func BenchmarkAddMap_50Keys(b *testing.B) {
+	m := make(map[string]int64, 50)
+	for i := 0; i < 50; i++ {
+		m[strconv.FormatInt(benchKeys[i], 10)] = benchVals[i]
+	}
+
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		clues.AddMap(ctx, m)
+	}
+}
+
 func BenchmarkIn_constMap(b *testing.B) {
 	ctx := context.Background()
 	dn := clues.In(ctx)
@@ -274,3 +291,31 @@ func BenchmarkIn_randSlice(b *testing.B) {
 	_ = dn
 	_ = s
 }
+
+func BenchmarkIn_deepMap(b *testing.B) {
+	ctx := context.Background()
+	for i := 0; i < 50; i++ {
+		ctx = clues.Add(ctx, strconv.Itoa(i), benchVals[i])
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		clues.In(ctx).Map()
+	}
+}
+
+func BenchmarkIn_deepScopedMap(b *testing.B) {
+	ctx := clues.Scope(context.Background(), "scope")
+	for i := 0; i < 50; i++ {
+		ctx = clues.Add(ctx, strconv.Itoa(i), benchVals[i])
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		clues.In(ctx).Map()
+	}
+}
@@ -2,11 +2,21 @@ package clues
 
 import (
 	"context"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkMetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
+	"github.com/alcionai/clues/cluerr"
 	"github.com/alcionai/clues/internal/node"
 )
 
@@ -180,3 +190,273 @@ func TestInherit(t *testing.T) {
 		})
 	}
 }
+
+func TestIsolate(t *testing.T) {
+	n := &node.Node{
+		OTEL: &node.OTELClient{
+			ServiceName: "test",
+		},
+	}
+
+	parent, cancel := context.WithCancel(node.EmbedInCtx(context.Background(), n))
+	parent = Add(parent, "k", "v")
+	parent = AddComment(parent, "comment")
+
+	isolated := Isolate(parent)
+	assert.Empty(t, In(isolated).Map())
+	assert.Empty(t, In(isolated).Comments())
+
+	in := node.FromCtx(isolated)
+	require.NotNil(t, in.OTEL)
+	assert.Equal(t, "test", in.OTEL.ServiceName)
+
+	// additions to the isolated ctx don't leak back into the parent.
+	isolated = Add(isolated, "iso", "lated")
+	assert.Equal(t, map[string]any{"iso": "lated"}, In(isolated).Map())
+	assert.NotContains(t, In(parent).Map(), "iso")
+
+	cancel()
+
+	select {
+	case <-isolated.Done():
+	default:
+		assert.Fail(t, "isolated ctx should be cancelled with its parent")
+	}
+
+	assert.Empty(t, In(Isolate(nil)).Map())
+}
+
+func TestNewSpan_WithStartTime(t *testing.T) {
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder))
+		n        = &node.Node{OTEL: &node.OTELClient{Tracer: provider.Tracer("test")}}
+		ctx      = node.EmbedInCtx(context.Background(), n)
+		start    = time.Now().Add(-time.Hour).Truncate(time.Second)
+	)
+
+	ctx = NewSpan().
+		WithStartTime(start).
+		Start(ctx, "historical", "k", "v")
+
+	assert.Equal(t, "v", In(ctx).Map()["k"])
+
+	CloseSpan(ctx)
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+
+	assert.Equal(t, "historical", ended[0].Name())
+	assert.True(t, start.Equal(ended[0].StartTime()), "span should start at the provided time")
+	assert.True(t, ended[0].EndTime().After(start))
+}
+
+func TestEndSpanWithError(t *testing.T) {
+	table := []struct {
+		name       string
+		err        error
+		expectCode codes.Code
+		expectDesc string
+	}{
+		{
+			name:       "default status",
+			err:        cluerr.New("oops"),
+			expectCode: codes.Error,
+			expectDesc: "oops",
+		},
+		{
+			name:       "embedded status",
+			err:        cluerr.New("oops").WithOTELStatus(codes.Ok, "expected failure"),
+			expectCode: codes.Ok,
+			expectDesc: "",
+		},
+		{
+			name:       "wrapped embedded status",
+			err:        cluerr.Wrap(cluerr.New("oops").WithOTELStatus(codes.Error, "custom"), "wrap"),
+			expectCode: codes.Error,
+			expectDesc: "custom",
+		},
+		{
+			name:       "outermost embedded status wins",
+			err:        cluerr.Wrap(cluerr.New("oops").WithOTELStatus(codes.Error, "inner"), "wrap").WithOTELStatus(codes.Error, "outer"),
+			expectCode: codes.Error,
+			expectDesc: "outer",
+		},
+		{
+			name:       "nil error",
+			err:        nil,
+			expectCode: codes.Unset,
+			expectDesc: "",
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				recorder = tracetest.NewSpanRecorder()
+				provider = sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder))
+				n        = &node.Node{OTEL: &node.OTELClient{Tracer: provider.Tracer("test")}}
+				ctx      = node.EmbedInCtx(context.Background(), n)
+			)
+
+			ctx = AddSpan(ctx, "span")
+			EndSpanWithError(ctx, test.err)
+
+			ended := recorder.Ended()
+			require.Len(t, ended, 1)
+
+			status := ended[0].Status()
+			assert.Equal(t, test.expectCode, status.Code)
+			assert.Equal(t, test.expectDesc, status.Description)
+
+			if test.err != nil {
+				require.Len(t, ended[0].Events(), 1)
+				assert.Equal(t, "exception", ended[0].Events()[0].Name)
+			}
+		})
+	}
+}
+
+func TestSetSpanError_noSpan(t *testing.T) {
+	assert.NotPanics(t, func() {
+		SetSpanError(context.Background(), cluerr.New("oops"))
+	})
+}
+
+func TestBindLabelCounterToMeter(t *testing.T) {
+	var (
+		reader   = sdkMetric.NewManualReader()
+		provider = sdkMetric.NewMeterProvider(sdkMetric.WithReader(reader))
+		n        = &node.Node{OTEL: &node.OTELClient{Meter: provider.Meter("test")}}
+		local    = &labelCounter{}
+		ctx      = node.EmbedInCtx(context.Background(), n)
+	)
+
+	ctx = AddLabelCounter(ctx, local)
+	ctx = BindLabelCounterToMeter(ctx)
+
+	cluerr.NewWC(ctx, "one").Label("foo")
+	cluerr.NewWC(ctx, "two").Label("foo", "bar")
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "clues.labels", m.Name)
+
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+
+	counts := map[string]int64{}
+
+	for _, dp := range sum.DataPoints {
+		label, _ := dp.Attributes.Value(attribute.Key("label"))
+		counts[label.AsString()] = dp.Value
+	}
+
+	assert.Equal(t, map[string]int64{"foo": 2, "bar": 1}, counts)
+	assert.Equal(
+		t,
+		map[string]int64{"foo": 2, "bar": 1},
+		local.counts,
+		"prior counter still receives counts")
+
+	// without otel, the ctx is unchanged.
+	bg := context.Background()
+	assert.Equal(t, bg, BindLabelCounterToMeter(bg))
+}
+
+func TestBindLabelCounterToMeter_keepsThresholds(t *testing.T) {
+	var (
+		reader   = sdkMetric.NewManualReader()
+		provider = sdkMetric.NewMeterProvider(sdkMetric.WithReader(reader))
+		n        = &node.Node{OTEL: &node.OTELClient{Meter: provider.Meter("test")}}
+		ctx      = node.EmbedInCtx(context.Background(), n)
+	)
+
+	ctx = WithLabelThreshold(ctx, "foo", 1)
+	ctx = BindLabelCounterToMeter(ctx)
+
+	cluerr.NewWC(ctx, "one").Label("foo")
+	assert.False(t, LabelExceeded(ctx, "foo"), "threshold not yet exceeded")
+
+	cluerr.NewWC(ctx, "two").Label("foo")
+	assert.True(t, LabelExceeded(ctx, "foo"), "threshold exceeded after binding a meter")
+}
+
+type labelCounter struct {
+	counts map[string]int64
+}
+
+func (lc *labelCounter) Add(key string, n int64) {
+	if lc.counts == nil {
+		lc.counts = map[string]int64{}
+	}
+
+	lc.counts[key] += n
+}
+
+func TestSetStrictMode(t *testing.T) {
+	warnings := make(chan string, 10)
+
+	node.SetStrictMode(true, func(msg string) { warnings <- msg })
+	defer SetStrictMode(false)
+
+	ctx := context.Background()
+
+	// the returned ctx is dropped, which strict mode should catch.
+	func() {
+		Add(ctx, "k", "v")
+	}()
+
+	// the returned ctx is read, which strict mode should ignore.
+	func() {
+		used := Add(ctx, "k", "v")
+		require.Equal(t, "v", In(used).Map()["k"])
+	}()
+
+	var warning string
+
+	for i := 0; i < 50 && len(warning) == 0; i++ {
+		runtime.GC()
+
+		select {
+		case warning = <-warnings:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	require.NotEmpty(t, warning, "expected a strict mode warning")
+	assert.Contains(t, warning, "clues_internal_test.go")
+
+	// give any further finalizers a chance to run.
+	runtime.GC()
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Empty(t, warnings, "only the dropped ctx produces a warning")
+}
+
+// run with -race to verify that shared nodes can be re-embedded
+// concurrently while strict mode is enabled.
+func TestSetStrictMode_concurrentDetach(t *testing.T) {
+	// the node is built before strict mode is enabled, so that it first
+	// gets tracked when the goroutines re-embed it.
+	ctx := Add(context.Background(), "k", "v")
+
+	node.SetStrictMode(true, func(string) {})
+	defer SetStrictMode(false)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "v", In(Detach(ctx)).Map()["k"])
+		}()
+	}
+
+	wg.Wait()
+}
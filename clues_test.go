@@ -3,15 +3,25 @@ package clues_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/alcionai/clues"
 	"github.com/alcionai/clues/cecrets"
+	"github.com/alcionai/clues/cluerr"
 	"github.com/alcionai/clues/internal/tester"
 )
 
@@ -88,6 +98,652 @@ func TestAddMap(t *testing.T) {
 	}
 }
 
+func TestAddMap_manyKeys(t *testing.T) {
+	var (
+		m      = map[string]int{}
+		expect = map[string]any{}
+	)
+
+	for i := 0; i < 50; i++ {
+		k := fmt.Sprintf("key_%d", i)
+		m[k] = i
+		expect[k] = strconv.Itoa(i)
+	}
+
+	ctx := clues.AddMap(context.Background(), m)
+	assert.Equal(t, expect, clues.In(ctx).Map())
+
+	// adding more keys to the same ctx extends the values.
+	ctx = clues.AddMap(ctx, map[string]int{"key_0": -1, "extra": 1})
+	expect["key_0"] = "-1"
+	expect["extra"] = "1"
+
+	assert.Equal(t, expect, clues.In(ctx).Map())
+}
+
+func TestAddStatic(t *testing.T) {
+	ctx := clues.AddStatic(context.Background(), map[string]any{
+		"version": "1.0",
+		"region":  "us-east",
+	})
+
+	assert.Equal(
+		t,
+		map[string]any{"version": "1.0", "region": "us-east"},
+		clues.In(ctx).Map())
+
+	// request values override static values
+	ctx = clues.Add(ctx, "region", "eu-west", "request_id", "rid")
+
+	assert.Equal(
+		t,
+		map[string]any{"version": "1.0", "region": "eu-west", "request_id": "rid"},
+		clues.In(ctx).Map())
+
+	// even when the static value gets added later
+	ctx = clues.AddStatic(ctx, map[string]any{"request_id": "static"})
+	assert.Equal(t, "rid", clues.In(ctx).Map()["request_id"])
+
+	// errors carry the static values
+	err := cluerr.NewWC(ctx, "err")
+	assert.Equal(t, "1.0", err.Values().Map()["version"])
+	assert.Equal(t, "eu-west", err.Values().Map()["region"])
+}
+
+func TestAddLazy(t *testing.T) {
+	calls := 0
+
+	ctx := clues.AddLazy(context.Background(), "lazy", func() any {
+		calls++
+		return "computed"
+	})
+	ctx = clues.Add(ctx, "k", "v")
+
+	require.Zero(t, calls, "func is not called before the values are read")
+
+	tester.MustEquals(t, tester.MSA{"lazy": "computed", "k": "v"}, clues.In(ctx).Map(), false)
+	require.Equal(t, 1, calls, "func is called when the values are read")
+
+	tester.MustEquals(t, tester.MSA{"lazy": "computed", "k": "v"}, clues.In(ctx).Map(), false)
+	require.Equal(t, 1, calls, "result is memoized")
+
+	err := cluerr.NewWC(ctx, "err")
+	tester.MustEquals(t, tester.MSA{"lazy": "computed", "k": "v"}, err.Values().Map(), false)
+	require.Equal(t, 1, calls, "result is memoized for errors")
+}
+
+func TestAddLazy_hooks(t *testing.T) {
+	t.Run("reject empty keys", func(t *testing.T) {
+		clues.SetRejectEmptyKeys(true)
+		defer clues.SetRejectEmptyKeys(false)
+
+		ctx := clues.AddLazy(context.Background(), "", func() any { return "v" })
+		assert.NotContains(t, clues.In(ctx).Map(), "")
+	})
+
+	t.Run("on add", func(t *testing.T) {
+		var (
+			calls int
+			added = map[string]any{}
+		)
+
+		clues.OnAdd(func(k string, v any) { added[k] = v })
+		defer clues.OnAdd(nil)
+
+		ctx := clues.AddLazy(context.Background(), "lazy", func() any {
+			calls++
+			return "computed"
+		})
+
+		assert.Equal(t, map[string]any{"lazy": "computed"}, added)
+		assert.Equal(t, "computed", clues.In(ctx).Map()["lazy"])
+		assert.Equal(t, 1, calls, "the hook shares the memoized result")
+	})
+}
+
+func TestAddIf(t *testing.T) {
+	table := []struct {
+		name    string
+		cond    bool
+		expectM tester.MSA
+	}{
+		{"true", true, tester.MSA{"k": "v"}},
+		{"false", false, tester.MSA{}},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			actx := clues.AddIf(ctx, test.cond, "k", "v")
+
+			tester.MustEquals(t, test.expectM, clues.In(actx).Map(), false)
+
+			if !test.cond && actx != ctx {
+				t.Error("expected ctx to be unchanged")
+			}
+		})
+	}
+}
+
+func TestAddIfNotZero(t *testing.T) {
+	var (
+		nilPtr *pointable
+		str    = "s"
+	)
+
+	table := []struct {
+		name   string
+		value  any
+		expect bool
+	}{
+		{"nil", nil, false},
+		{"empty string", "", false},
+		{"string", "v", true},
+		{"zero int", 0, false},
+		{"int", 1, true},
+		{"zero float", 0.0, false},
+		{"float", 0.1, true},
+		{"false", false, false},
+		{"true", true, true},
+		{"empty struct", pointable{}, false},
+		{"nil pointer", nilPtr, false},
+		{"pointer", &str, true},
+		{"nil slice", []string(nil), false},
+		{"empty slice", []string{}, true},
+		{"nil map", map[string]string(nil), false},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			actx := clues.AddIfNotZero(ctx, "k", test.value)
+
+			_, ok := clues.In(actx).Map()["k"]
+			if ok != test.expect {
+				t.Errorf("expected key to be added [%v], got [%v]", test.expect, ok)
+			}
+
+			if !test.expect && actx != ctx {
+				t.Error("expected ctx to be unchanged")
+			}
+		})
+	}
+}
+
+func TestAdd_rejectEmptyKeys(t *testing.T) {
+	table := []struct {
+		name    string
+		reject  bool
+		expectM tester.MSA
+	}{
+		{"disabled", false, tester.MSA{"": "v", "k": "v"}},
+		{"enabled", true, tester.MSA{"k": "v"}},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			clues.SetRejectEmptyKeys(test.reject)
+			defer clues.SetRejectEmptyKeys(false)
+
+			ctx := clues.Add(context.Background(), "", "v", "k", "v")
+			tester.MustEquals(t, test.expectM, clues.In(ctx).Map(), false)
+
+			ctx = clues.AddMap(context.Background(), map[string]string{"": "v", "k": "v"})
+			tester.MustEquals(t, test.expectM, clues.In(ctx).Map(), false)
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var (
+		into   = clues.Add(context.Background(), "into", "into", "shared", "into")
+		first  = clues.Add(context.Background(), "first", "first", "shared", "first", "from", "first")
+		second = clues.AddSpan(context.Background(), "second", "second", "second", "shared", "second", "from", "second")
+	)
+
+	merged := clues.Merge(into, first, second)
+
+	assert.Equal(
+		t,
+		map[string]any{
+			"into":   "into",
+			"first":  "first",
+			"second": "second",
+			// into's own values win over all.
+			"shared": "into",
+			// later from ctxs win over earlier ones.
+			"from": "second",
+		},
+		clues.In(merged).Map(),
+		"the trace of the from ctxs is not merged")
+
+	// reversing the from ctxs reverses their precedence.
+	merged = clues.Merge(into, second, first)
+	assert.Equal(t, "first", clues.In(merged).Map()["from"])
+	assert.Equal(t, "into", clues.In(merged).Map()["shared"])
+
+	// the into ctx is left untouched.
+	assert.Equal(t, map[string]any{"into": "into", "shared": "into"}, clues.In(into).Map())
+
+	assert.Equal(t, into, clues.Merge(into), "nothing to merge")
+}
+
+func TestCheckpoint(t *testing.T) {
+	ctx := clues.Add(context.Background(), "k", "v")
+	ctx = clues.AddComment(ctx, "before")
+
+	ctx, restore := clues.Checkpoint(ctx)
+	expect := clues.In(ctx).Map()
+
+	for i := 0; i < 3; i++ {
+		ctx = restore()
+		assert.Equal(t, expect, clues.In(ctx).Map())
+		assert.Len(t, clues.In(ctx).Comments(), 1)
+
+		ctx = clues.Add(ctx, "iter", i, "k", "overwritten")
+		ctx = clues.AddComment(ctx, "during %d", i)
+
+		assert.Equal(t, "overwritten", clues.In(ctx).Map()["k"])
+		assert.Len(t, clues.In(ctx).Comments(), 2)
+	}
+
+	ctx = restore()
+	assert.Equal(t, expect, clues.In(ctx).Map())
+	assert.NotContains(t, clues.In(ctx).Map(), "iter")
+	assert.Len(t, clues.In(ctx).Comments(), 1)
+
+	ctx, restore = clues.Checkpoint(nil)
+	assert.NotNil(t, ctx)
+	assert.Empty(t, clues.In(restore()).Map())
+}
+
+func TestOnCtxDone(t *testing.T) {
+	errCause := errors.New("cause")
+
+	table := []struct {
+		name   string
+		cancel func(context.CancelCauseFunc)
+		expect error
+	}{
+		{
+			name:   "with cause",
+			cancel: func(cancel context.CancelCauseFunc) { cancel(errCause) },
+			expect: errCause,
+		},
+		{
+			name:   "without cause",
+			cancel: func(cancel context.CancelCauseFunc) { cancel(nil) },
+			expect: context.Canceled,
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancelCause(context.Background())
+			ctx = clues.Add(ctx, "k", "v")
+
+			var (
+				causes = make(chan error, 1)
+				values = make(chan map[string]any, 1)
+			)
+
+			clues.OnCtxDone(ctx, func(cause error) {
+				values <- clues.In(ctx).Map()
+				causes <- cause
+			})
+
+			test.cancel(cancel)
+
+			select {
+			case cause := <-causes:
+				assert.ErrorIs(t, cause, test.expect)
+				assert.Equal(t, "v", (<-values)["k"])
+			case <-time.After(5 * time.Second):
+				require.Fail(t, "fn was not called after cancellation")
+			}
+		})
+	}
+}
+
+func TestSetValueTransformer(t *testing.T) {
+	clues.SetValueTransformer(func(k string, v any) any {
+		if s, ok := v.(string); ok && k == "shout" {
+			return strings.ToUpper(s)
+		}
+
+		return v
+	})
+	defer clues.SetValueTransformer(nil)
+
+	ctx := clues.Add(context.Background(), "shout", "hello", "quiet", "hello")
+	ctx = clues.Add(ctx, "number", 1)
+
+	require.Equal(
+		t,
+		map[string]any{
+			"shout":  "HELLO",
+			"quiet":  "hello",
+			"number": "1",
+		},
+		clues.In(ctx).Map())
+
+	// errors flatten their values the same way.
+	err := cluerr.New("err").With("shout", "error")
+	require.Equal(t, "ERROR", err.Values().Map()["shout"])
+
+	// the stored value is left untouched.
+	clues.SetValueTransformer(nil)
+	require.Equal(t, "hello", clues.In(ctx).Map()["shout"])
+}
+
+func TestSetValueTransformer_runsOnce(t *testing.T) {
+	clues.SetValueTransformer(func(k string, v any) any {
+		return fmt.Sprintf("<%v>", v)
+	})
+	defer clues.SetValueTransformer(nil)
+
+	var (
+		ctx    = clues.Add(context.Background(), "k", "v")
+		other  = clues.Add(context.Background(), "from", "f")
+		err    = cluerr.NewWC(ctx, "err")
+		stack  = cluerr.Stack(cluerr.WrapWC(ctx, err, "wrap"))
+		merged = clues.Merge(ctx, other)
+	)
+
+	assert.Equal(t, "<v>", clues.In(ctx).Map()["k"])
+	assert.Equal(t, "<v>", err.Values().Map()["k"])
+	assert.Equal(t, "<v>", cluerr.CluesIn(err).Map()["k"])
+	assert.Equal(t, "<v>", cluerr.CluesIn(stack).Map()["k"])
+	assert.Equal(t, "<v>", err.UserValues()["k"])
+	assert.Equal(t, "<v>", cluerr.ToCore(stack).Values["k"])
+	assert.Equal(t, "<v>", clues.In(merged).Map()["k"])
+	assert.Equal(t, "<f>", clues.In(merged).Map()["from"])
+}
+
+func TestScope(t *testing.T) {
+	var (
+		ctx     = clues.Add(context.Background(), "global", "g")
+		tenantA = clues.Add(clues.Scope(ctx, "a"), "tenant", "a", "a_only", "a")
+		// tenant B incorrectly reuses tenant A's ctx.
+		tenantB = clues.Add(clues.Scope(tenantA, "b"), "tenant", "b")
+		// returning to scope a shows the values of scope a.
+		backToA = clues.Add(clues.Scope(tenantB, "a"), "k", "v")
+	)
+
+	assert.Equal(t, map[string]any{"global": "g"}, clues.In(ctx).Map())
+	assert.Equal(
+		t,
+		map[string]any{"global": "g", "tenant": "a", "a_only": "a"},
+		clues.In(tenantA).Map())
+	assert.Equal(
+		t,
+		map[string]any{"global": "g", "tenant": "b"},
+		clues.In(tenantB).Map())
+	assert.Equal(
+		t,
+		map[string]any{"global": "g", "tenant": "a", "a_only": "a", "k": "v"},
+		clues.In(backToA).Map())
+
+	err := cluerr.NewWC(tenantB, "err")
+	assert.NotContains(t, err.Values().Map(), "a_only")
+
+	// empty scope IDs are ignored
+	assert.Equal(t, clues.In(tenantB).Map(), clues.In(clues.Scope(tenantB, "")).Map())
+}
+
+func TestScope_isolation(t *testing.T) {
+	ctx := clues.AddComment(context.Background(), "global comment")
+	ctx = clues.AddStatic(ctx, map[string]any{"global_static": "g"})
+	ctx = clues.PushOp(ctx, "global-op")
+
+	a := clues.Scope(ctx, "tenantA")
+	a = clues.AddComment(a, "a comment")
+	a = clues.PushOp(a, "tenantA-op")
+	a = clues.AddStatic(a, map[string]any{"a_static": "a"})
+
+	b := clues.Scope(a, "tenantB")
+	b = clues.AddComment(b, "b comment")
+
+	t.Run("comments", func(t *testing.T) {
+		msgs := func(ctx context.Context) []string {
+			ms := []string{}
+
+			for _, c := range clues.In(ctx).Comments() {
+				ms = append(ms, c.Message)
+			}
+
+			return ms
+		}
+
+		assert.Equal(t, []string{"global comment", "a comment"}, msgs(a))
+		assert.Equal(t, []string{"global comment", "b comment"}, msgs(b))
+
+		errMsgs := []string{}
+		for _, c := range cluerr.NewWC(b, "err").Comments() {
+			errMsgs = append(errMsgs, c.Message)
+		}
+
+		assert.NotContains(t, errMsgs, "a comment")
+	})
+
+	t.Run("ops", func(t *testing.T) {
+		assert.Equal(t, []string{"global-op", "tenantA-op"}, clues.Ops(a))
+		assert.Equal(t, []string{"global-op"}, clues.Ops(b))
+		assert.Equal(t, "global-op", clues.In(b).Map()["clues_ops"])
+		assert.Equal(t, "global-op", cluerr.NewWC(b, "err").Values().Map()["clues_ops"])
+	})
+
+	t.Run("static values", func(t *testing.T) {
+		assert.Equal(t, "a", clues.In(a).Map()["a_static"])
+		assert.Equal(t, "g", clues.In(b).Map()["global_static"])
+		assert.NotContains(t, clues.In(b).Map(), "a_static")
+		assert.NotContains(t, cluerr.NewWC(b, "err").Values().Map(), "a_static")
+	})
+}
+
+func TestPushOp(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		handler = clues.PushOp(ctx, "handler")
+		service = clues.PushOp(clues.Add(handler, "k", "v"), "service")
+		repo    = clues.PushOp(service, "repo")
+	)
+
+	assert.Empty(t, clues.Ops(ctx))
+	assert.Equal(t, []string{"handler"}, clues.Ops(handler))
+	assert.Equal(t, []string{"handler", "service"}, clues.Ops(service))
+	assert.Equal(t, []string{"handler", "service", "repo"}, clues.Ops(repo))
+
+	assert.Equal(t, "handler>service>repo", clues.In(repo).Map()["clues_ops"])
+	assert.Equal(t, "v", clues.In(repo).Map()["k"])
+	assert.NotContains(t, clues.In(ctx).Map(), "clues_ops")
+
+	// empty ops are ignored
+	assert.Equal(t, clues.Ops(repo), clues.Ops(clues.PushOp(repo, "")))
+
+	// the op stack doesn't collide with a user's own "ops" value
+	user := clues.Add(repo, "ops", "mine")
+	assert.Equal(t, "mine", clues.In(user).Map()["ops"])
+	assert.Equal(t, "handler>service>repo", clues.In(user).Map()["clues_ops"])
+	assert.Equal(t, "mine", cluerr.NewWC(user, "err").UserValues()["ops"])
+	assert.NotContains(t, cluerr.NewWC(user, "err").UserValues(), "clues_ops")
+	assert.Equal(t, "mine", clues.In(clues.Merge(ctx, user)).Map()["ops"])
+}
+
+func TestRegisterSentinel(t *testing.T) {
+	var (
+		sentinel   = errors.New("registered sentinel")
+		unregister = errors.New("unregistered sentinel")
+	)
+
+	clues.RegisterSentinel(sentinel, "sentinel", "auto")
+
+	table := []struct {
+		name   string
+		err    error
+		expect map[string]struct{}
+	}{
+		{
+			name:   "sentinel",
+			err:    sentinel,
+			expect: map[string]struct{}{"sentinel": {}, "auto": {}},
+		},
+		{
+			name:   "stacked",
+			err:    cluerr.Stack(sentinel, cluerr.New("err")).Label("explicit"),
+			expect: map[string]struct{}{"sentinel": {}, "auto": {}, "explicit": {}},
+		},
+		{
+			name:   "wrapped stack",
+			err:    fmt.Errorf("wrap: %w", cluerr.Wrap(cluerr.Stack(cluerr.New("err"), sentinel), "wrap")),
+			expect: map[string]struct{}{"sentinel": {}, "auto": {}},
+		},
+		{
+			name:   "unregistered",
+			err:    cluerr.Stack(unregister, cluerr.New("err")),
+			expect: map[string]struct{}{},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, cluerr.Labels(test.err))
+
+			for label := range test.expect {
+				assert.True(t, cluerr.HasLabel(test.err, label), label)
+			}
+
+			assert.False(t, cluerr.HasLabel(test.err, "missing"))
+		})
+	}
+}
+
+func TestPromoteLabels(t *testing.T) {
+	var (
+		base  = cluerr.New("base").Label("db.deadlock")
+		stack = cluerr.Stack(cluerr.New("other").Label("other"), base)
+		err   = cluerr.Wrap(stack, "wrap").Label("top")
+	)
+
+	table := []struct {
+		name   string
+		err    error
+		expect map[string]struct{}
+	}{
+		{
+			name:   "clues error",
+			err:    err,
+			expect: map[string]struct{}{"db.deadlock": {}, "other": {}, "top": {}},
+		},
+		{
+			name:   "fmt wrapped",
+			err:    fmt.Errorf("fmt: %w", stack),
+			expect: map[string]struct{}{"db.deadlock": {}, "other": {}},
+		},
+		{
+			name:   "no labels",
+			err:    errors.New("no labels"),
+			expect: map[string]struct{}{},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			promoted := clues.PromoteLabels(test.err)
+
+			top, ok := promoted.(*cluerr.Err)
+			require.True(t, ok, "promoted error is a clues error")
+
+			assert.Equal(t, test.err.Error(), promoted.Error())
+			assert.Equal(t, test.expect, cluerr.Labels(promoted))
+
+			// the top-most error holds every label on its own, without
+			// needing to walk the tree.
+			assert.Equal(t, test.expect, cluerr.FlattenCores(top)[0].Labels)
+		})
+	}
+
+	assert.Nil(t, clues.PromoteLabels(nil))
+}
+
+func TestSetOddArgsPolicy(t *testing.T) {
+	table := []struct {
+		name   string
+		policy clues.OddArgsPolicy
+		expect map[string]any
+	}{
+		{
+			name:   "nil",
+			policy: clues.OddArgsNil,
+			expect: map[string]any{"k": "v", "dangling": nil},
+		},
+		{
+			name:   "drop",
+			policy: clues.OddArgsDrop,
+			expect: map[string]any{"k": "v"},
+		},
+		{
+			name:   "missing",
+			policy: clues.OddArgsMissing,
+			expect: map[string]any{"k": "v", "dangling": "<missing>"},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			clues.SetOddArgsPolicy(test.policy)
+			defer clues.SetOddArgsPolicy(clues.OddArgsNil)
+
+			ctx := clues.Add(context.Background(), "k", "v", "dangling")
+			assert.Equal(t, test.expect, clues.In(ctx).Map())
+
+			err := cluerr.New("err").With("k", "v", "dangling")
+			assert.Equal(t, test.expect, err.Values().Map())
+		})
+	}
+}
+
+func TestSetTimeFormat(t *testing.T) {
+	when := time.Date(2024, 2, 3, 4, 5, 6, 7, time.UTC)
+
+	ctx := clues.Add(context.Background(), "when", when)
+	assert.Equal(t, "2024-02-03T04:05:06.000000007Z", clues.In(ctx).Map()["when"])
+
+	clues.SetTimeFormat(time.Kitchen)
+	defer clues.SetTimeFormat("")
+
+	ctx = clues.Add(context.Background(), "when", when)
+	assert.Equal(t, "4:05AM", clues.In(ctx).Map()["when"])
+
+	err := cluerr.New("err").With("when", when)
+	assert.Equal(t, "4:05AM", err.Values().Map()["when"])
+}
+
+func TestOnAdd(t *testing.T) {
+	added := map[string]any{}
+
+	clues.OnAdd(func(k string, v any) {
+		added[k] = v
+	})
+	defer clues.OnAdd(nil)
+
+	ctx := clues.Add(context.Background(), "k", "v", 1, 2)
+	clues.AddMap(ctx, map[string]int{"mk": 3})
+	cluerr.New("err").With("ek", "ev")
+
+	assert.Equal(
+		t,
+		map[string]any{
+			"k":  "v",
+			"1":  "2",
+			"mk": "3",
+			"ek": "ev",
+		},
+		added)
+
+	// unset hooks don't fire.
+	clues.OnAdd(nil)
+	clear(added)
+
+	clues.Add(context.Background(), "k", "v")
+	cluerr.New("err").With("ek", "ev")
+
+	assert.Empty(t, added)
+}
+
 func TestAddSpan(t *testing.T) {
 	table := []struct {
 		name        string
@@ -156,6 +812,286 @@ func TestAddSpan(t *testing.T) {
 	}
 }
 
+func TestInjectReceiveTrace_metadata(t *testing.T) {
+	ocfg := clues.OTELConfig{GRPCEndpoint: "localhost:4317"}
+
+	ctx, err := clues.InitializeOTEL(context.Background(), "metadata", ocfg)
+	require.NoError(t, err, "initializing otel")
+
+	ctx = clues.AddSpan(ctx, "sender")
+	defer clues.CloseSpan(ctx)
+
+	sent := trace.SpanContextFromContext(ctx)
+	require.True(t, sent.IsValid(), "sender span context is valid")
+
+	md := clues.InjectTrace(ctx, metadata.MD{})
+	require.NotEmpty(t, md.Get("traceparent"), "traceparent injected into metadata")
+
+	rctx := clues.ReceiveTrace(context.Background(), md)
+	received := trace.SpanContextFromContext(rctx)
+
+	require.True(t, received.IsRemote(), "received span context is remote")
+	require.Equal(t, sent.TraceID(), received.TraceID(), "trace id continuity")
+	require.Equal(t, sent.SpanID(), received.SpanID(), "parent span id continuity")
+}
+
+func TestWithCorrelationID(t *testing.T) {
+	table := []struct {
+		name   string
+		id     string
+		expect assert.ValueAssertionFunc
+	}{
+		{
+			name: "provided",
+			id:   "cid",
+			expect: func(t assert.TestingT, v any, _ ...any) bool {
+				return assert.Equal(t, "cid", v)
+			},
+		},
+		{
+			name:   "generated",
+			id:     "",
+			expect: assert.NotEmpty,
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, err := clues.WithCorrelationID(context.Background(), test.id)
+			require.NoError(t, err)
+
+			id := clues.In(ctx).Map()[clues.CorrelationIDKey]
+			test.expect(t, id)
+
+			bag := baggage.FromContext(ctx)
+			assert.Equal(t, id, bag.Member(clues.CorrelationIDKey).Value())
+
+			cerr := cluerr.NewWC(ctx, "err")
+			assert.Equal(t, id, cerr.Values().Map()[clues.CorrelationIDKey])
+
+			cerr = cluerr.WrapWC(ctx, errors.New("err"), "wrap")
+			assert.Equal(t, id, cerr.Values().Map()[clues.CorrelationIDKey])
+		})
+	}
+}
+
+func TestAddBaggage(t *testing.T) {
+	clues.SetBaggageByteLimit(64)
+	defer clues.SetBaggageByteLimit(0)
+
+	ctx, err := clues.AddBaggage(context.Background(), "k", "v")
+	require.NoError(t, err)
+
+	ctx, err = clues.AddBaggageProps(ctx, "pk", "pv", map[string]string{"prop": "val"})
+	require.NoError(t, err)
+
+	bag := baggage.FromContext(ctx)
+	require.Equal(t, "v", bag.Member("k").Value())
+	require.Equal(t, "pv", bag.Member("pk").Value())
+	require.Len(t, bag.Member("pk").Properties(), 1)
+
+	table := []struct {
+		name string
+		add  func(context.Context) (context.Context, error)
+	}{
+		{
+			name: "oversized value",
+			add: func(ctx context.Context) (context.Context, error) {
+				return clues.AddBaggage(ctx, "big", strings.Repeat("v", 64))
+			},
+		},
+		{
+			name: "oversized props",
+			add: func(ctx context.Context) (context.Context, error) {
+				return clues.AddBaggageProps(
+					ctx,
+					"small",
+					"v",
+					map[string]string{"big": strings.Repeat("v", 64)})
+			},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.add(ctx)
+			require.Error(t, err)
+			require.True(t, cluerr.HasLabel(err, clues.BaggageLimitLabel), "error has baggage limit label")
+			require.ElementsMatch(t, bag.Members(), baggage.FromContext(result).Members(), "baggage is unchanged")
+		})
+	}
+
+	// each addition fits on its own, but not both together.
+	ctx, err = clues.AddBaggage(ctx, "a", strings.Repeat("v", 25))
+	require.NoError(t, err)
+
+	_, err = clues.AddBaggage(ctx, "b", strings.Repeat("v", 25))
+	require.True(t, cluerr.HasLabel(err, clues.BaggageLimitLabel), "cumulative additions exceed the limit")
+
+	// invalid members error out, but aren't size related.
+	_, err = clues.AddBaggage(ctx, "", "v")
+	require.Error(t, err)
+	require.False(t, cluerr.HasLabel(err, clues.BaggageLimitLabel), "invalid key is not a limit error")
+}
+
+// errors produced by the clues package are cluerr errors, so chains which
+// mix them with errors built directly in cluerr aggregate as one.
+func TestCluesErrors_inCluerrChain(t *testing.T) {
+	clues.SetBaggageByteLimit(16)
+	defer clues.SetBaggageByteLimit(0)
+
+	ctx := clues.Add(context.Background(), "ctx_k", "ctx_v")
+
+	_, cerr := clues.AddBaggage(ctx, "big", strings.Repeat("v", 32))
+	require.Error(t, cerr)
+
+	err := cluerr.Stack(cluerr.New("outer").With("k", "v").Label("outer"), cerr)
+
+	values := cluerr.CluesIn(err).Map()
+	assert.Equal(t, "v", values["k"])
+	assert.Equal(t, "ctx_v", values["ctx_k"])
+	assert.Equal(t, "big", values["baggage_key"])
+
+	assert.Contains(t, cluerr.Labels(err), "outer")
+	assert.Contains(t, cluerr.Labels(err), clues.BaggageLimitLabel)
+
+	assert.ErrorIs(t, err, cerr)
+
+	var target *cluerr.Err
+	require.ErrorAs(t, cerr, &target)
+}
+
+type labelCounter map[string]int64
+
+func (lc labelCounter) Add(k string, n int64) {
+	lc[k] = lc[k] + n
+}
+
+func TestAddLabelCounter(t *testing.T) {
+	var (
+		counter = labelCounter{}
+		ctx     = clues.AddLabelCounter(context.Background(), counter)
+	)
+
+	cluerr.NewWC(ctx, "one").Label("a", "b", "a")
+	cluerr.NewWC(ctx, "two").Label("a").Label("a")
+	cluerr.New("uncounted").Label("a")
+
+	require.Equal(t, labelCounter{"a": 2, "b": 1}, counter)
+}
+
+func TestAddLabelCounter_detached(t *testing.T) {
+	var (
+		counter     = labelCounter{}
+		ctx, cancel = context.WithCancel(context.Background())
+		wg          sync.WaitGroup
+	)
+
+	ctx = clues.AddLabelCounter(ctx, counter)
+	ctx = clues.Add(ctx, "k", "v")
+	detached := clues.Detach(ctx)
+
+	cancel()
+	require.NoError(t, detached.Err(), "detached ctx is not canceled with its parent")
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		err := cluerr.NewWC(detached, "in goroutine").Label("a")
+		assert.Equal(t, "v", err.Values().Map()["k"])
+
+		spanned := clues.AddSpan(detached, "span")
+		cluerr.NewWC(spanned, "in span").Label("b")
+
+		cluerr.NewWC(clues.Isolate(detached), "isolated").Label("c")
+	}()
+
+	wg.Wait()
+
+	require.Equal(t, labelCounter{"a": 1, "b": 1, "c": 1}, counter)
+}
+
+func TestAddLabelCounter_chained(t *testing.T) {
+	var (
+		first  = labelCounter{}
+		second = labelCounter{}
+		ctx    = clues.AddLabelCounter(context.Background(), first)
+	)
+
+	ctx = clues.WithLabelThreshold(ctx, "a", 1)
+	ctx = clues.AddLabelCounter(ctx, second)
+
+	cluerr.NewWC(ctx, "one").Label("a")
+	cluerr.NewWC(ctx, "two").Label("a")
+
+	require.Equal(t, labelCounter{"a": 2}, first, "the prior counter still receives counts")
+	require.Equal(t, labelCounter{"a": 2}, second)
+	require.True(t, clues.LabelExceeded(ctx, "a"), "the prior threshold is kept")
+}
+
+func TestAddLabelCounter_withCluesWithoutCounter(t *testing.T) {
+	var (
+		counter = labelCounter{}
+		ctx     = clues.AddLabelCounter(context.Background(), counter)
+	)
+
+	cluerr.NewWC(ctx, "counted").
+		WithClues(context.Background()).
+		Label("a")
+
+	require.Equal(t, labelCounter{"a": 1}, counter, "a ctx without a counter keeps the existing one")
+}
+
+func TestWithLabelThreshold(t *testing.T) {
+	var (
+		counter = labelCounter{}
+		ctx     = clues.AddLabelCounter(context.Background(), counter)
+	)
+
+	ctx = clues.WithLabelThreshold(ctx, "fnords", 2)
+	ctx = clues.WithLabelThreshold(ctx, "smarf", 5)
+
+	for i := 0; i < 2; i++ {
+		cluerr.NewWC(ctx, "fnords").Label("fnords", "fnords")
+		require.False(t, clues.LabelExceeded(ctx, "fnords"), "threshold not yet exceeded")
+	}
+
+	cluerr.NewWC(ctx, "fnords").Label("fnords")
+	require.True(t, clues.LabelExceeded(ctx, "fnords"), "threshold exceeded")
+	require.False(t, clues.LabelExceeded(ctx, "smarf"), "other label threshold not exceeded")
+	require.False(t, clues.LabelExceeded(ctx, "unset"), "label without a threshold")
+	require.False(t, clues.LabelExceeded(context.Background(), "fnords"), "ctx without a threshold")
+
+	// the original counter continues to receive counts
+	require.Equal(t, labelCounter{"fnords": 3}, counter)
+}
+
+func TestSetSampled(t *testing.T) {
+	ctx := context.Background()
+	require.False(t, clues.IsSampled(ctx), "no decision")
+
+	ocfg := clues.OTELConfig{GRPCEndpoint: "localhost:4317"}
+
+	ctx, err := clues.InitializeOTEL(ctx, "sampling", ocfg)
+	require.NoError(t, err, "initializing otel")
+
+	for _, sampled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("%v", sampled), func(t *testing.T) {
+			sctx := clues.SetSampled(ctx, sampled)
+			require.Equal(t, sampled, clues.IsSampled(sctx))
+
+			sctx = clues.Add(sctx, "k", "v")
+			require.Equal(t, sampled, clues.IsSampled(sctx), "inherited by descendants")
+
+			sctx = clues.AddSpan(sctx, "span")
+			defer clues.CloseSpan(sctx)
+
+			spanCtx := trace.SpanContextFromContext(sctx)
+			require.Equal(t, sampled, spanCtx.IsSampled(), "span sampling follows the ctx")
+		})
+	}
+}
+
 func TestImmutableCtx(t *testing.T) {
 	var (
 		ctx     = context.Background()
@@ -416,6 +1352,143 @@ func TestAddComment_trace(t *testing.T) {
 	commentMatches(t, expected, stack)
 }
 
+func TestSetCommentFormatter(t *testing.T) {
+	type jsonComment struct {
+		Caller  string `json:"caller"`
+		File    string `json:"file"`
+		Message string `json:"message"`
+	}
+
+	clues.SetCommentFormatter(func(c clues.Comment) string {
+		bs, err := json.Marshal(jsonComment{c.Caller, c.File, c.Message})
+		require.NoError(t, err)
+
+		return string(bs)
+	})
+	defer clues.SetCommentFormatter(nil)
+
+	ctx := clues.AddComment(context.Background(), "one")
+	ctx = clues.AddComment(ctx, "two")
+
+	lines := strings.Split(clues.In(ctx).Comments().String(), "\n")
+	require.Len(t, lines, 2)
+
+	for i, msg := range []string{"one", "two"} {
+		var jc jsonComment
+
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &jc))
+		assert.Equal(t, "TestSetCommentFormatter", jc.Caller)
+		assert.Contains(t, jc.File, "clues_test.go:")
+		assert.Equal(t, msg, jc.Message)
+	}
+
+	// nil restores the default format.
+	clues.SetCommentFormatter(nil)
+
+	commentMatches(
+		t,
+		commentRE("TestSetCommentFormatter", `\S*clues_test.go`, "one$"),
+		clues.In(clues.AddComment(context.Background(), "one")).Comments().String())
+}
+
+func TestSetNodeIDFunc(t *testing.T) {
+	var count int
+
+	clues.SetNodeIDFunc(func() string {
+		count++
+		return fmt.Sprintf("id_%d", count)
+	})
+	defer clues.SetNodeIDFunc(nil)
+
+	ctx := clues.AddComment(context.Background(), "first")
+	ctx = clues.Add(ctx, "k", "v")
+	ctx = clues.AddSpan(ctx, "")
+	ctx = clues.AddComment(ctx, "second")
+
+	require.Equal(
+		t,
+		map[string]any{
+			"k":           "v",
+			"clues_trace": "id_1,id_2,id_3",
+		},
+		clues.In(ctx).Map())
+
+	// the default generator is restored with a nil func.
+	clues.SetNodeIDFunc(nil)
+
+	ctx = clues.AddComment(context.Background(), "third")
+	require.NotEqual(t, "id_4", clues.In(ctx).Map()["clues_trace"])
+}
+
+func TestSetTraceKeyAndSeparator(t *testing.T) {
+	var count int
+
+	clues.SetNodeIDFunc(func() string {
+		count++
+		return fmt.Sprintf("id_%d", count)
+	})
+	defer clues.SetNodeIDFunc(nil)
+
+	clues.SetTraceKey("trace")
+	defer clues.SetTraceKey("")
+
+	clues.SetTraceSeparator("|")
+	defer clues.SetTraceSeparator("")
+
+	ctx := clues.AddComment(context.Background(), "first")
+	ctx = clues.Add(ctx, "k", "v")
+	ctx = clues.AddComment(ctx, "second")
+
+	require.Equal(
+		t,
+		map[string]any{
+			"k":     "v",
+			"trace": "id_1|id_2",
+		},
+		clues.In(ctx).Map())
+
+	// empty values restore the defaults.
+	clues.SetTraceKey("")
+	clues.SetTraceSeparator("")
+
+	require.Equal(
+		t,
+		map[string]any{
+			"k":           "v",
+			"clues_trace": "id_1,id_2",
+		},
+		clues.In(ctx).Map())
+}
+
+func TestExtractTrace(t *testing.T) {
+	var count int
+
+	clues.SetNodeIDFunc(func() string {
+		count++
+		return fmt.Sprintf("id_%d", count)
+	})
+	defer clues.SetNodeIDFunc(nil)
+
+	ctx := clues.AddComment(context.Background(), "first")
+	ctx = clues.Add(ctx, "k", "v")
+	ctx = clues.AddComment(ctx, "second")
+
+	m := clues.In(ctx).Map()
+	assert.Equal(t, "id_1,id_2", m[clues.TraceKey])
+	assert.Equal(t, []string{"id_1", "id_2"}, clues.ExtractTrace(m))
+
+	clues.SetTraceKey("trace")
+	defer clues.SetTraceKey("")
+
+	clues.SetTraceSeparator("|")
+	defer clues.SetTraceSeparator("")
+
+	assert.Equal(t, []string{"id_1", "id_2"}, clues.ExtractTrace(clues.In(ctx).Map()))
+
+	assert.Nil(t, clues.ExtractTrace(clues.In(context.Background()).Map()))
+	assert.Nil(t, clues.ExtractTrace(nil))
+}
+
 func TestAddAgent(t *testing.T) {
 	ctx := context.Background()
 	ctx = clues.Add(ctx, "one", 1)
@@ -2,8 +2,8 @@ package node
 
 import (
 	"fmt"
-	"slices"
 	"strings"
+	"sync/atomic"
 )
 
 // ---------------------------------------------------------------------------
@@ -54,7 +54,7 @@ func (dn *Node) AddComment(
 	}
 
 	spawn := dn.SpawnDescendant()
-	spawn.ID = randomNodeID()
+	spawn.ID = makeNodeID()
 	spawn.Comment = NewComment(depth+1, msg, vs...)
 
 	return spawn
@@ -63,20 +63,47 @@ func (dn *Node) AddComment(
 // CommentHistory allows us to put a stringer on a slice of CommentHistory.
 type CommentHistory []Comment
 
+// commentFormatter renders each comment within CommentHistory.String().
+// A nil pointer means formatComment is used.
+var commentFormatter atomic.Pointer[func(Comment) string]
+
+// SetCommentFormatter replaces the func used to render each comment when
+// stringifying a CommentHistory.  If fn is nil, the default format is
+// restored.
+func SetCommentFormatter(fn func(Comment) string) {
+	if fn == nil {
+		fn = formatComment
+	}
+
+	commentFormatter.Store(&fn)
+}
+
+// formatComment is the default comment formatter.  The format is:
+//
+//	<caller> - <file>:<line>
+//	  <message>
+func formatComment(c Comment) string {
+	return c.Caller + " - " + c.File + "\n\t" + c.Message
+}
+
 // String formats the slice of comments as a stack, much like you'd see
 // with an error stacktrace.  Comments are listed top-to-bottom from first-
-// to-last.
+// to-last, and each comment is rendered by the comment formatter.
 //
-// The format for each comment in the stack is:
+// The default format for each comment in the stack is:
 //
 //	<caller> - <file>:<line>
 //	  <message>
 func (cs CommentHistory) String() string {
-	result := []string{}
+	format := formatComment
+	if fn := commentFormatter.Load(); fn != nil {
+		format = *fn
+	}
+
+	result := make([]string, 0, len(cs))
 
 	for _, c := range cs {
-		result = append(result, c.Caller+" - "+c.File)
-		result = append(result, "\t"+c.Message)
+		result = append(result, format(c))
 	}
 
 	return strings.Join(result, "\n")
@@ -84,22 +111,120 @@ func (cs CommentHistory) String() string {
 
 // Comments retrieves the full ancestor comment chain.
 // The return value is ordered from the first added comment (closest to
-// the root) to the most recent one (closest to the leaf).
+// the root) to the most recent one (closest to the leaf).  Comments from
+// other scopes are skipped.
 func (dn *Node) Comments() CommentHistory {
 	result := CommentHistory{}
 
-	if !dn.Comment.IsEmpty() {
-		result = append(result, dn.Comment)
+	dn.walkLineage(func(n *Node) {
+		if !n.Comment.IsEmpty() {
+			result = append(result, n.Comment)
+		}
+	})
+
+	return result
+}
+
+// ---------------------------------------------------------------------------
+// serialization
+// ---------------------------------------------------------------------------
+
+const (
+	// commentEscape precedes any delimiter (or escape) that appears
+	// within a comment's properties.
+	commentEscape = '\\'
+	// commentFieldSep delimits the properties of a single comment.
+	// ascii unit separator.
+	commentFieldSep = '\x1f'
+	// commentSep delimits each comment in the history.
+	// ascii record separator.
+	commentSep = '\x1e'
+)
+
+var commentEscaper = strings.NewReplacer(
+	string(commentEscape), string(commentEscape)+string(commentEscape),
+	string(commentFieldSep), string(commentEscape)+string(commentFieldSep),
+	string(commentSep), string(commentEscape)+string(commentSep))
+
+// encode flattens the comment history into a single delimited string.
+// The caller, file, and message of each comment are separated by the
+// field delimiter, and each comment is separated by the comment delimiter.
+// Any delimiters present in a comment are escaped.
+func (cs CommentHistory) encode() string {
+	sb := strings.Builder{}
+
+	for i, c := range cs {
+		if i > 0 {
+			sb.WriteByte(commentSep)
+		}
+
+		sb.WriteString(commentEscaper.Replace(c.Caller))
+		sb.WriteByte(commentFieldSep)
+		sb.WriteString(commentEscaper.Replace(c.File))
+		sb.WriteByte(commentFieldSep)
+		sb.WriteString(commentEscaper.Replace(c.Message))
+	}
+
+	return sb.String()
+}
+
+// decodeComments is the inverse of CommentHistory.encode().  It splits
+// the string into an ordered set of comments, unescaping any delimiters
+// found within each comment.
+func decodeComments(s string) CommentHistory {
+	result := CommentHistory{}
+
+	if len(s) == 0 {
+		return result
 	}
 
-	for dn.Parent != nil {
-		dn = dn.Parent
-		if !dn.Comment.IsEmpty() {
-			result = append(result, dn.Comment)
+	var (
+		fields  = []string{}
+		sb      = strings.Builder{}
+		escaped bool
+	)
+
+	// delimiters are all single-byte ascii, so we can safely walk
+	// the bytes instead of the runes.
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+
+		switch {
+		case escaped:
+			sb.WriteByte(b)
+			escaped = false
+		case b == commentEscape:
+			escaped = true
+		case b == commentFieldSep:
+			fields = append(fields, sb.String())
+			sb.Reset()
+		case b == commentSep:
+			fields = append(fields, sb.String())
+			sb.Reset()
+
+			result = append(result, commentFromFields(fields))
+			fields = []string{}
+		default:
+			sb.WriteByte(b)
 		}
 	}
 
-	slices.Reverse(result)
+	fields = append(fields, sb.String())
+	result = append(result, commentFromFields(fields))
 
 	return result
 }
+
+// commentFromFields builds a comment out of the ordered
+// [caller, file, message] fields.
+func commentFromFields(fields []string) Comment {
+	for len(fields) < 3 {
+		fields = append(fields, "")
+	}
+
+	return Comment{
+		Caller:  fields[0],
+		File:    fields[1],
+		Message: fields[2],
+	}
+}
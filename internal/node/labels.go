@@ -0,0 +1,184 @@
+package node
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// LabelMetricID is the id of the otel counter which receives label counts
+// from BindLabelCounterToMeter.
+const LabelMetricID = "clues.labels"
+
+// ---------------------------------------------------------------------------
+// label counting
+// ---------------------------------------------------------------------------
+
+// Adder is the interface used to count labels.  Each time a new label is
+// added to an error holding a counter, Add(label, 1) gets called.
+type Adder interface {
+	Add(key string, n int64)
+}
+
+// chainedAdder is implemented by the Adders which wrap another Adder,
+// passing all additions along to the next counter in the chain.
+type chainedAdder interface {
+	next() Adder
+}
+
+// labelThreshold records the count of a single label against its limit.
+type labelThreshold struct {
+	limit int64
+	count atomic.Int64
+}
+
+// thresholdCounter is an Adder that tracks the count of a thresholded
+// label, and passes all additions along to the next counter in the chain.
+type thresholdCounter struct {
+	label     string
+	threshold *labelThreshold
+	chained   Adder
+}
+
+func (tc *thresholdCounter) Add(key string, n int64) {
+	if key == tc.label {
+		tc.threshold.count.Add(n)
+	}
+
+	if tc.chained != nil {
+		tc.chained.Add(key, n)
+	}
+}
+
+func (tc *thresholdCounter) next() Adder {
+	return tc.chained
+}
+
+// userCounter is an Adder that passes all additions to a counter provided
+// by the end user, and then along to the next counter in the chain.
+type userCounter struct {
+	counter Adder
+	chained Adder
+}
+
+func (uc *userCounter) Add(key string, n int64) {
+	uc.counter.Add(key, n)
+
+	if uc.chained != nil {
+		uc.chained.Add(key, n)
+	}
+}
+
+func (uc *userCounter) next() Adder {
+	return uc.chained
+}
+
+// AddLabelCounter embeds the counter in a new descendant node.  Any
+// counter already present in the node, including label thresholds,
+// continues to receive all counts.
+func (dn *Node) AddLabelCounter(counter Adder) *Node {
+	if counter != nil && dn.LabelCounter != nil {
+		counter = &userCounter{
+			counter: counter,
+			chained: dn.LabelCounter,
+		}
+	}
+
+	spawn := dn.SpawnDescendant()
+
+	if counter != nil {
+		spawn.LabelCounter = counter
+	}
+
+	return spawn
+}
+
+// meterCounter is an Adder that increments an otel counter, attributing
+// each increment to the label, and passes all additions along to the next
+// counter in the chain.
+type meterCounter struct {
+	counter metric.Int64Counter
+	chained Adder
+}
+
+func (mc *meterCounter) Add(key string, n int64) {
+	mc.counter.Add(
+		context.Background(),
+		n,
+		metric.WithAttributes(attribute.String("label", key)))
+
+	if mc.chained != nil {
+		mc.chained.Add(key, n)
+	}
+}
+
+func (mc *meterCounter) next() Adder {
+	return mc.chained
+}
+
+// BindLabelCounterToMeter embeds a counter in a new descendant node which
+// increments the otel counter "clues.labels" for each counted label.  Any
+// counter already present in the node continues to receive all counts.
+// Returns an error if otel was not initialized.
+func (dn *Node) BindLabelCounterToMeter() (*Node, error) {
+	meter := dn.OTELMeter()
+	if meter == nil {
+		return dn, errors.New("otel meter not initialized")
+	}
+
+	ctr, err := meter.Int64Counter(
+		LabelMetricID,
+		metric.WithDescription("number of times each error label was added"))
+	if err != nil {
+		return dn, errors.Wrap(err, "making label counter")
+	}
+
+	spawn := dn.SpawnDescendant()
+	spawn.LabelCounter = &meterCounter{
+		counter: ctr,
+		chained: dn.LabelCounter,
+	}
+
+	return spawn, nil
+}
+
+// WithLabelThreshold embeds a counter in a new descendant node which
+// tracks the number of times the label gets counted.  Any counter
+// already present in the node continues to receive all counts.
+func (dn *Node) WithLabelThreshold(label string, n int64) *Node {
+	spawn := dn.SpawnDescendant()
+	spawn.LabelCounter = &thresholdCounter{
+		label:     label,
+		threshold: &labelThreshold{limit: n},
+		chained:   dn.LabelCounter,
+	}
+
+	return spawn
+}
+
+// LabelExceeded returns true if the count of the label is greater than
+// the most recent threshold set for that label.  Returns false if no
+// threshold was set.
+func (dn *Node) LabelExceeded(label string) bool {
+	if dn == nil {
+		return false
+	}
+
+	for counter := dn.LabelCounter; counter != nil; {
+		if tc, ok := counter.(*thresholdCounter); ok && tc.label == label {
+			return tc.threshold.count.Load() > tc.threshold.limit
+		}
+
+		ca, ok := counter.(chainedAdder)
+		if !ok {
+			return false
+		}
+
+		counter = ca.next()
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+package node
+
+import "sync"
+
+// ---------------------------------------------------------------------------
+// lazy values
+// ---------------------------------------------------------------------------
+
+// lazyValue holds a func which produces a value the first time the value
+// is requested.  The result is cached for all later requests.
+type lazyValue struct {
+	once sync.Once
+	fn   func() any
+	v    any
+}
+
+func (lv *lazyValue) get() any {
+	lv.once.Do(func() {
+		if lv.fn != nil {
+			lv.v = lv.fn()
+		}
+	})
+
+	return lv.v
+}
+
+// resolve produces the value, calling the func in any lazy value.
+func resolve(v any) any {
+	if lv, ok := v.(*lazyValue); ok {
+		return lv.get()
+	}
+
+	return v
+}
+
+// AddLazy adds a value to a new descendant node which is only computed
+// when the node's values are flattened (ie: when calling Map()).  The
+// func is called at most once, and its result is cached in the node.
+//
+// Lazy values are not propagated onto the current span, since doing so
+// would require computing the value.  The same checks as AddValues apply
+// to the key.  If an onAdd hook is set, the value gets computed right
+// away so that the hook can receive it.
+func (dn *Node) AddLazy(key string, fn func() any) *Node {
+	if len(key) == 0 && rejectEmptyKeys.Load() {
+		return dn
+	}
+
+	lv := &lazyValue{fn: fn}
+
+	if hook := onAdd.Load(); hook != nil {
+		(*hook)(key, lv.get())
+	}
+
+	spawn := dn.SpawnDescendant()
+	spawn.SetValues(map[string]any{key: lv})
+
+	return spawn
+}
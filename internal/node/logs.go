@@ -0,0 +1,34 @@
+package node
+
+import "golang.org/x/exp/maps"
+
+// ---------------------------------------------------------------------------
+// log defaults
+// ---------------------------------------------------------------------------
+
+// AddLogDefaults embeds the log defaults in a new descendant node.  Since
+// log defaults live on the tree, every descendant (including the nodes
+// produced by AddSpan) inherits them.
+func (dn *Node) AddLogDefaults(m map[string]any) *Node {
+	if len(m) == 0 {
+		return dn
+	}
+
+	spawn := dn.SpawnDescendant()
+	spawn.LogDefaults = maps.Clone(m)
+
+	return spawn
+}
+
+// LogDefaultsMap flattens the tree of log defaults into a map.  Descendant
+// nodes take priority over ancestors in cases of collision.  Log defaults
+// from other scopes are skipped.
+func (dn *Node) LogDefaultsMap() map[string]any {
+	m := map[string]any{}
+
+	dn.walkLineage(func(n *Node) {
+		maps.Copy(m, n.LogDefaults)
+	})
+
+	return m
+}
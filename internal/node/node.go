@@ -3,7 +3,9 @@ package node
 import (
 	"context"
 	"encoding/json"
+	"slices"
 	"strings"
+	"sync/atomic"
 
 	"github.com/alcionai/clues/internal/stringify"
 	"github.com/google/uuid"
@@ -43,6 +45,15 @@ type Node struct {
 	// get replaced at arbitrary points.
 	Span trace.Span
 
+	// spanAttrKeys tracks the distinct attribute keys that have been set on
+	// the current Span.  It is shared by all nodes which hold the same Span.
+	spanAttrKeys *spanAttrKeys
+
+	// spanParent is the node that was current when the Span was added.
+	// It holds the parent span, which is restored when the Span closes
+	// with CloseSpanReturnParent.
+	spanParent *Node
+
 	// ids are optional and are used primarily as tracing markers.
 	// if empty, the trace for that node will get skipped when building the
 	// full trace along the node's ancestry path in the tree.
@@ -66,6 +77,44 @@ type Node struct {
 	// variations of data from each other, in case users need to compare differences
 	// on the same keys.  That's not the goal for Agents, exactly, but it is capable.
 	Agents map[string]*Agent
+
+	// StaticValues are key:value pairs, generally bound at startup, which
+	// appear in the flattened Values at the lowest priority.  Any Values
+	// in the tree with the same key take priority over them.
+	StaticValues map[string]any
+
+	// LogDefaults are key:value pairs which get included in every log
+	// produced from this node or its descendants.  Unlike Values, they
+	// are not attached to errors or spans.
+	LogDefaults map[string]any
+
+	// ScopeID marks the node as the start of a new scope.  Values added
+	// to the tree beneath a scope are hidden from nodes in other scopes.
+	ScopeID string
+
+	// Op is the name of the operation which this node was spawned to
+	// record.  The ops along the ancestry path, from oldest ancestor to
+	// the current node, produce the operation stack.
+	Op string
+
+	// LabelCounter is an optional hook that counts the labels added to
+	// errors which are built using this node.
+	LabelCounter Adder
+
+	// Sampled records an explicit, per-request sampling decision that is
+	// shared by both trace sampling and debug logging.  If nil, no decision
+	// has been made.
+	Sampled *bool
+
+	// strict is only populated while strict mode is enabled.  It tracks
+	// whether the node was ever read back out of a ctx.  Nodes can get
+	// re-embedded while shared (ex: by Detach), so the tracker is set
+	// atomically.
+	strict atomic.Pointer[strictTracker]
+
+	// hasScope is true if this node, or any of its ancestors, starts a
+	// scope.  Walks of unscoped trees skip the scope checks.
+	hasScope bool
 }
 
 // SpawnDescendant generates a new node that is a descendant of the current
@@ -79,11 +128,139 @@ func (dn *Node) SpawnDescendant() *Node {
 	}
 
 	return &Node{
-		Parent: dn,
-		OTEL:   dn.OTEL,
-		Span:   dn.Span,
-		Agents: agents,
+		Parent:       dn,
+		OTEL:         dn.OTEL,
+		Span:         dn.Span,
+		spanAttrKeys: dn.spanAttrKeys,
+		spanParent:   dn.spanParent,
+		Agents:       agents,
+		LabelCounter: dn.LabelCounter,
+		Sampled:      dn.Sampled,
+		hasScope:     dn.hasScope,
+	}
+}
+
+// ---------------------------------------------------------------------------
+// configuration
+// ---------------------------------------------------------------------------
+
+// rejectEmptyKeys, when true, causes the node to drop any key:value pair
+// whose key is the empty string.
+var rejectEmptyKeys atomic.Bool
+
+// SetRejectEmptyKeys toggles whether empty-string keys are dropped when
+// adding values to a node.  Defaults to false.
+func SetRejectEmptyKeys(reject bool) {
+	rejectEmptyKeys.Store(reject)
+}
+
+const (
+	// DefaultTraceKey is the default key under which Map() records
+	// the node IDs.
+	DefaultTraceKey       = "clues_trace"
+	defaultTraceSeparator = ","
+)
+
+var (
+	// traceKey holds the key under which Map() records the node IDs.  A
+	// nil pointer means the DefaultTraceKey is used.
+	traceKey atomic.Pointer[string]
+	// traceSeparator holds the separator which joins the node IDs in the
+	// trace value.  A nil pointer means the default separator is used.
+	traceSeparator atomic.Pointer[string]
+)
+
+// SetTraceKey sets the key under which Map() records the node IDs.  An
+// empty key restores the default, "clues_trace".
+func SetTraceKey(key string) {
+	if len(key) == 0 {
+		key = DefaultTraceKey
+	}
+
+	traceKey.Store(&key)
+}
+
+// SetTraceSeparator sets the separator used to join the node IDs in the
+// trace value.  An empty separator restores the default, ",".
+func SetTraceSeparator(sep string) {
+	if len(sep) == 0 {
+		sep = defaultTraceSeparator
+	}
+
+	traceSeparator.Store(&sep)
+}
+
+// loadTraceKey returns the current trace key.
+func loadTraceKey() string {
+	if key := traceKey.Load(); key != nil {
+		return *key
+	}
+
+	return DefaultTraceKey
+}
+
+// loadTraceSeparator returns the current trace separator.
+func loadTraceSeparator() string {
+	if sep := traceSeparator.Load(); sep != nil {
+		return *sep
+	}
+
+	return defaultTraceSeparator
+}
+
+// agentsKey is the key under which the agent values get added by Map().
+const agentsKey = "agents"
+
+// TraceIDKey is the key under which errors record the otel trace ID that
+// was active in the ctx when they were built.
+const TraceIDKey = "otel_trace_id"
+
+// ReservedKeys returns the keys which clues adds to the flattened values
+// on its own, as opposed to the keys added by the end user.
+func ReservedKeys() []string {
+	return []string{loadTraceKey(), agentsKey, TraceIDKey, OpsKey}
+}
+
+// ExtractTrace retrieves the node IDs from a map produced by Map(), using
+// the current trace key and separator.  Returns nil if the map holds no
+// trace.
+func ExtractTrace(m map[string]any) []string {
+	trace, ok := m[loadTraceKey()].(string)
+	if !ok || len(trace) == 0 {
+		return nil
+	}
+
+	return strings.Split(trace, loadTraceSeparator())
+}
+
+// valueTransformer, when non-nil, reshapes each value as the node gets
+// flattened by Map().
+var valueTransformer atomic.Pointer[func(key string, value any) any]
+
+// SetValueTransformer sets the func used to transform values when the
+// node is flattened.  A nil func disables transformation.
+func SetValueTransformer(fn func(key string, value any) any) {
+	if fn == nil {
+		valueTransformer.Store(nil)
+		return
+	}
+
+	valueTransformer.Store(&fn)
+}
+
+// onAdd, when non-nil, is called with each key and value added to a node
+// by AddValues.
+var onAdd atomic.Pointer[func(key string, value any)]
+
+// SetOnAdd sets the func called with each key and value added to a node.
+// A nil func disables the hook.
+func SetOnAdd(fn func(key string, value any)) {
+	if fn == nil {
+		onAdd.Store(nil)
+		return
 	}
+
+	onAdd.Store(&fn)
 }
 
 // ---------------------------------------------------------------------------
@@ -97,6 +274,17 @@ func (dn *Node) AddValues(m map[string]any) *Node {
 		m = map[string]any{}
 	}
 
+	if _, ok := m[""]; ok && rejectEmptyKeys.Load() {
+		m = maps.Clone(m)
+		delete(m, "")
+	}
+
+	if fn := onAdd.Load(); fn != nil {
+		for k, v := range m {
+			(*fn)(k, v)
+		}
+	}
+
 	spawn := dn.SpawnDescendant()
 	spawn.SetValues(m)
 	spawn.AddSpanAttributes(m)
@@ -104,6 +292,31 @@ func (dn *Node) AddValues(m map[string]any) *Node {
 	return spawn
 }
 
+// AddStaticValues embeds the static values in a new descendant node.
+// Static values always take the lowest priority when the node is
+// flattened, regardless of where in the tree they were added.
+func (dn *Node) AddStaticValues(m map[string]any) *Node {
+	if len(m) == 0 {
+		return dn
+	}
+
+	if _, ok := m[""]; ok && rejectEmptyKeys.Load() {
+		m = maps.Clone(m)
+		delete(m, "")
+	}
+
+	if fn := onAdd.Load(); fn != nil {
+		for k, v := range m {
+			(*fn)(k, v)
+		}
+	}
+
+	spawn := dn.SpawnDescendant()
+	spawn.StaticValues = maps.Clone(m)
+
+	return spawn
+}
+
 // SetValues is generally a helper called by addValues.  In
 // certain corner cases (like agents) it may get called directly.
 func (dn *Node) SetValues(m map[string]any) {
@@ -112,16 +325,24 @@ func (dn *Node) SetValues(m map[string]any) {
 	}
 
 	if len(dn.Values) == 0 {
-		dn.Values = map[string]any{}
+		dn.Values = make(map[string]any, len(m))
 	}
 
 	maps.Copy(dn.Values, m)
 }
 
+// SetSampled records the sampling decision in a new descendant node.
+func (dn *Node) SetSampled(sampled bool) *Node {
+	spawn := dn.SpawnDescendant()
+	spawn.Sampled = &sampled
+
+	return spawn
+}
+
 // AppendToTree adds a new leaf with the provided name.
 func (dn *Node) AppendToTree(name string) *Node {
 	if name == "" {
-		name = randomNodeID()
+		name = makeNodeID()
 	}
 
 	spawn := dn.SpawnDescendant()
@@ -135,39 +356,173 @@ func (dn *Node) AppendToTree(name string) *Node {
 // ---------------------------------------------------------------------------
 
 // RunLineage runs the fn on every valueNode in the ancestry tree,
-// starting at the root and ending at the node.
+// starting at the root and ending at the node.  Nodes which belong to a
+// different scope than the node are skipped.  Nodes outside of any scope
+// are always included.
 func (dn *Node) RunLineage(fn func(id string, vs map[string]any)) {
+	dn.walkLineage(func(n *Node) {
+		fn(n.ID, n.Values)
+	})
+}
+
+// walkLineage runs fn on each node in the ancestry tree which is visible
+// within the node's scope, starting at the root and ending at the node.
+// Every walk of the ancestry which gathers data must go through
+// walkLineage, so that data never leaks between scopes.
+func (dn *Node) walkLineage(fn func(n *Node)) {
 	if dn == nil {
 		return
 	}
 
+	if !dn.hasScope {
+		dn.walkAll(fn)
+		return
+	}
+
+	dn.walkScoped(dn.Scope(), fn)
+}
+
+// walkAll runs fn on every node in the ancestry tree, starting at the
+// root and ending at the node.
+func (dn *Node) walkAll(fn func(n *Node)) {
+	if dn.Parent != nil {
+		dn.Parent.walkAll(fn)
+	}
+
+	fn(dn)
+}
+
+// walkScoped runs fn on every node in the ancestry tree which is either
+// outside of any scope, or within the provided scope, starting at the
+// root and ending at the node.  Returns the scope of the node.
+func (dn *Node) walkScoped(scope string, fn func(n *Node)) string {
+	var nodeScope string
+
 	if dn.Parent != nil {
-		dn.Parent.RunLineage(fn)
+		nodeScope = dn.Parent.walkScoped(scope, fn)
+	}
+
+	if len(dn.ScopeID) > 0 {
+		nodeScope = dn.ScopeID
+	}
+
+	if len(nodeScope) == 0 || nodeScope == scope {
+		fn(dn)
+	}
+
+	return nodeScope
+}
+
+// Scope returns the ID of the nearest scope in the node's ancestry,
+// including the node itself.  Returns an empty string if the node
+// doesn't belong to a scope.
+func (dn *Node) Scope() string {
+	for ; dn != nil; dn = dn.Parent {
+		if len(dn.ScopeID) > 0 {
+			return dn.ScopeID
+		}
+	}
+
+	return ""
+}
+
+// AddScope embeds the scope ID in a new descendant node, which starts a
+// new scope.  Empty scope IDs are ignored.
+func (dn *Node) AddScope(scopeID string) *Node {
+	if len(scopeID) == 0 {
+		return dn
 	}
 
-	fn(dn.ID, dn.Values)
+	spawn := dn.SpawnDescendant()
+	spawn.ScopeID = scopeID
+	spawn.hasScope = true
+
+	return spawn
+}
+
+// IsSampled returns the sampling decision recorded in the node.  The
+// second return value is false if no decision was recorded.
+func (dn *Node) IsSampled() (sampled, ok bool) {
+	if dn == nil || dn.Sampled == nil {
+		return false, false
+	}
+
+	return *dn.Sampled, true
 }
 
 // Map flattens the tree of node.values into a map.  Descendant nodes
-// take priority over ancestors in cases of collision.
+// take priority over ancestors in cases of collision.  If a value
+// transformer is set, it reshapes each value, other than those under
+// the reserved keys.  Map is the outward-facing view of the values;
+// use RawMap when copying the values into another node.
 func (dn *Node) Map() map[string]any {
+	m := dn.RawMap()
+
+	transform := valueTransformer.Load()
+	if transform == nil {
+		return m
+	}
+
+	reserved := ReservedKeys()
+
+	for k, v := range m {
+		if !slices.Contains(reserved, k) {
+			m[k] = (*transform)(k, v)
+		}
+	}
+
+	return m
+}
+
+// RawMap flattens the tree of node.values into a map, the same as Map,
+// except that the value transformer is not applied.  Values copied from
+// one node into another (ex: from a ctx into an error) must come from
+// RawMap, so that the transformer runs only once, when the values are
+// finally read out with Map.
+func (dn *Node) RawMap() map[string]any {
 	var (
 		m       = map[string]any{}
+		static  map[string]any
 		nodeIDs = []string{}
+		ops     []string
 	)
 
-	dn.RunLineage(func(id string, vs map[string]any) {
-		if len(id) > 0 {
-			nodeIDs = append(nodeIDs, id)
+	dn.walkLineage(func(n *Node) {
+		if len(n.ID) > 0 {
+			nodeIDs = append(nodeIDs, n.ID)
 		}
 
-		for k, v := range vs {
-			m[k] = v
+		if len(n.Op) > 0 {
+			ops = append(ops, n.Op)
+		}
+
+		for k, v := range n.Values {
+			m[k] = resolve(v)
+		}
+
+		if len(n.StaticValues) > 0 {
+			if static == nil {
+				static = map[string]any{}
+			}
+
+			maps.Copy(static, n.StaticValues)
 		}
 	})
 
+	// static values take the lowest priority, regardless of where in the
+	// tree they were added.
+	for k, v := range static {
+		if _, ok := m[k]; !ok {
+			m[k] = resolve(v)
+		}
+	}
+
 	if len(nodeIDs) > 0 {
-		m["clues_trace"] = strings.Join(nodeIDs, ",")
+		m[loadTraceKey()] = strings.Join(nodeIDs, loadTraceSeparator())
+	}
+
+	if len(ops) > 0 {
+		m[OpsKey] = strings.Join(ops, opsSeparator)
 	}
 
 	if len(dn.Agents) == 0 {
@@ -180,7 +535,7 @@ func (dn *Node) Map() map[string]any {
 		agentVals[agent.ID] = agent.Data.Map()
 	}
 
-	m["agents"] = agentVals
+	m[agentsKey] = agentVals
 
 	return m
 }
@@ -189,7 +544,19 @@ func (dn *Node) Map() map[string]any {
 // indices contain the keys, and all odd indices contain values.  Descendant
 // nodes take priority over ancestors in cases of collision.
 func (dn *Node) Slice() []any {
+	return sliceOf(dn.Map())
+}
+
+// MapAndSlice produces both the Map() and Slice() of the node from a single
+// walk of the tree.  Callers which need both pay for only one walk.
+func (dn *Node) MapAndSlice() (map[string]any, []any) {
 	m := dn.Map()
+	return m, sliceOf(m)
+}
+
+// sliceOf flattens the map into a slice where all even indices contain
+// the keys, and all odd indices contain values.
+func sliceOf(m map[string]any) []any {
 	s := make([]any, 0, 2*len(m))
 
 	for k, v := range m {
@@ -258,11 +625,18 @@ func FromCtx(ctx context.Context) *Node {
 		return &Node{}
 	}
 
-	return dn.(*Node)
+	n := dn.(*Node)
+	n.markRead()
+
+	return n
 }
 
 // EmbedInCtx adds the node in the context, and returns the updated context.
+// Callers are expected to be the public clues funcs, which are themselves
+// called by the end user.
 func EmbedInCtx(ctx context.Context, dn *Node) context.Context {
+	// strict mode records the file:line of the end user's call.
+	trackStrict(dn, 2)
 	return context.WithValue(ctx, defaultCtxKey, dn)
 }
 
@@ -270,6 +644,29 @@ func EmbedInCtx(ctx context.Context, dn *Node) context.Context {
 // helpers
 // ---------------------------------------------------------------------------
 
+// nodeIDFunc produces the IDs for nodes that weren't given a name.
+// A nil pointer means randomNodeID is used.
+var nodeIDFunc atomic.Pointer[func() string]
+
+// SetNodeIDFunc replaces the func used to generate node IDs.  If fn is
+// nil, the default random ID generator is restored.
+func SetNodeIDFunc(fn func() string) {
+	if fn == nil {
+		fn = randomNodeID
+	}
+
+	nodeIDFunc.Store(&fn)
+}
+
+// makeNodeID produces a new node ID using the current nodeIDFunc.
+func makeNodeID() string {
+	if fn := nodeIDFunc.Load(); fn != nil {
+		return (*fn)()
+	}
+
+	return randomNodeID()
+}
+
 // randomNodeID generates a random hash of 8 characters for use as a node ID.
 func randomNodeID() string {
 	uns := uuid.NewString()
@@ -285,8 +682,31 @@ type nodeCore struct {
 	OTELServiceName string `json:"otelServiceName"`
 	// TODO: investigate if map[string]string is really the best structure here.
 	// maybe we can get away with a map[string]any, or a []byte slice?
-	Values   map[string]string `json:"values"`
-	Comments []Comment         `json:"comments"`
+	Values map[string]string `json:"values"`
+	// Comments holds the full comment history, flattened into a single
+	// delimited string.  See CommentHistory.encode() for the format.
+	Comments encodedComments `json:"comments"`
+}
+
+// encodedComments is a comment history flattened by CommentHistory.encode().
+// Older releases serialized the history as an array of comments, so both
+// forms are accepted when unmarshaling.
+type encodedComments string
+
+func (ec *encodedComments) UnmarshalJSON(bs []byte) error {
+	if len(bs) == 0 || bs[0] != '[' {
+		return json.Unmarshal(bs, (*string)(ec))
+	}
+
+	legacy := CommentHistory{}
+
+	if err := json.Unmarshal(bs, &legacy); err != nil {
+		return err
+	}
+
+	*ec = encodedComments(legacy.encode())
+
+	return nil
 }
 
 // Bytes serializes the Node to a slice of bytes.
@@ -310,10 +730,10 @@ func (dn *Node) Bytes() ([]byte, error) {
 	core := nodeCore{
 		OTELServiceName: serviceName,
 		Values:          map[string]string{},
-		Comments:        dn.Comments(),
+		Comments:        encodedComments(dn.Comments().encode()),
 	}
 
-	for k, v := range dn.Map() {
+	for k, v := range dn.RawMap() {
 		core.Values[k] = stringify.Marshal(v, false)
 	}
 
@@ -330,15 +750,25 @@ func FromBytes(bs []byte) (*Node, error) {
 		return nil, err
 	}
 
-	node := Node{
-		// FIXME: do something with the serialized commments.
-		// I'm punting on this for now because I want to figure
-		// out the best middle ground between avoiding a slice of
-		// comments in each node for serialization sake (they
-		// are supposed to be one-comment-per-node to use the tree
-		// for ordering instead of the parameter), and keeping
-		// the full comment history available.  Probably just
-		// need to introduce a delimiter.
+	node := Node{}
+
+	// nodes hold only one comment each, so the comment history
+	// is rebuilt as a chain of ancestors, with the most recent
+	// comment held by the returned node.
+	comments := decodeComments(string(core.Comments))
+
+	if len(comments) > 0 {
+		var parent *Node
+
+		for _, c := range comments[:len(comments)-1] {
+			parent = &Node{
+				Parent:  parent,
+				Comment: c,
+			}
+		}
+
+		node.Parent = parent
+		node.Comment = comments[len(comments)-1]
 	}
 
 	if len(core.Values) > 0 {
@@ -2,6 +2,8 @@ package node
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,6 +49,27 @@ func TestNode_Init(t *testing.T) {
 	}
 }
 
+func TestNode_MapAndSlice(t *testing.T) {
+	dn := (&Node{}).
+		AddValues(map[string]any{"a": 1, "b": 2}).
+		AppendToTree("id").
+		AddValues(map[string]any{"b": 3, "c": "c"})
+
+	m, s := dn.MapAndSlice()
+
+	assert.Equal(t, dn.Map(), m)
+	assert.ElementsMatch(t, dn.Slice(), s)
+	require.Len(t, s, 2*len(m))
+
+	for i := 0; i < len(s); i += 2 {
+		assert.Equal(t, m[s[i].(string)], s[i+1])
+	}
+
+	m, s = (&Node{}).MapAndSlice()
+	assert.Empty(t, m)
+	assert.Empty(t, s)
+}
+
 func TestBytes(t *testing.T) {
 	table := []struct {
 		name                 string
@@ -69,7 +92,7 @@ func TestBytes(t *testing.T) {
 			node: func() *Node {
 				return &Node{}
 			},
-			expectSerialized:     []byte(`{"otelServiceName":"","values":{},"comments":[]}`),
+			expectSerialized:     []byte(`{"otelServiceName":"","values":{},"comments":""}`),
 			expectDeserialized:   &Node{},
 			expectDeserializeErr: require.NoError,
 		},
@@ -93,7 +116,7 @@ func TestBytes(t *testing.T) {
 			},
 			expectSerialized: []byte(`{"otelServiceName":"serviceName",` +
 				`"values":{"fisher":"flannigan","fitzbog":""},` +
-				`"comments":[{"Caller":"i am caller","File":"i am file","Message":"i am message"}]}`),
+				`"comments":"i am caller\u001fi am file\u001fi am message"}`),
 			expectDeserialized: &Node{
 				OTEL: &OTELClient{
 					ServiceName: "serviceName",
@@ -102,6 +125,11 @@ func TestBytes(t *testing.T) {
 					"fisher":  "flannigan",
 					"fitzbog": "",
 				},
+				Comment: Comment{
+					Caller:  "i am caller",
+					File:    "i am file",
+					Message: "i am message",
+				},
 			},
 			expectDeserializeErr: require.NoError,
 		},
@@ -125,3 +153,187 @@ func TestBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestBytes_comments(t *testing.T) {
+	table := []struct {
+		name     string
+		comments CommentHistory
+	}{
+		{
+			name:     "none",
+			comments: CommentHistory{},
+		},
+		{
+			name: "single",
+			comments: CommentHistory{
+				{Caller: "caller", File: "file.go:1", Message: "message"},
+			},
+		},
+		{
+			name: "multiple",
+			comments: CommentHistory{
+				{Caller: "first", File: "file.go:1", Message: "one"},
+				{Caller: "second", File: "file.go:2", Message: "two"},
+				{Caller: "third", File: "file.go:3", Message: "three"},
+			},
+		},
+		{
+			name: "contains delimiters",
+			comments: CommentHistory{
+				{Caller: "fi\x1eeld", File: "fi\x1fle", Message: "mess\x1e\x1fage"},
+				{Caller: "\x1f", File: "\x1e", Message: "\x1f\x1e\x1f"},
+			},
+		},
+		{
+			name: "contains escapes",
+			comments: CommentHistory{
+				{Caller: "\\", File: "\\\x1f", Message: "trailing\\"},
+				{Caller: "a\\\\b", File: "", Message: "\\\x1e\\"},
+			},
+		},
+		{
+			name: "empty properties",
+			comments: CommentHistory{
+				{Caller: "", File: "", Message: "message"},
+				{Caller: "caller", File: "", Message: "message"},
+			},
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			dn := &Node{}
+
+			for _, c := range test.comments {
+				dn = &Node{Parent: dn, Comment: c}
+			}
+
+			serialized, err := dn.Bytes()
+			require.NoError(t, err)
+
+			deserialized, err := FromBytes(serialized)
+			require.NoError(t, err)
+			assert.Equal(t, test.comments, deserialized.Comments())
+		})
+	}
+}
+
+func TestFromBytes_legacyComments(t *testing.T) {
+	// comments were serialized as an array of comment objects before
+	// they were flattened into a delimited string.
+	legacy := []byte(`{
+		"otelServiceName": "",
+		"values": {"k": "v"},
+		"comments": [
+			{"Caller": "first", "File": "file.go:1", "Message": "one"},
+			{"Caller": "second", "File": "file.go:2", "Message": "two"}
+		]
+	}`)
+
+	dn, err := FromBytes(legacy)
+	require.NoError(t, err)
+
+	assert.Equal(t, "v", dn.Map()["k"])
+	assert.Equal(
+		t,
+		CommentHistory{
+			{Caller: "first", File: "file.go:1", Message: "one"},
+			{Caller: "second", File: "file.go:2", Message: "two"},
+		},
+		dn.Comments())
+
+	// a null comment history is treated as empty.
+	dn, err = FromBytes([]byte(`{"values": {}, "comments": null}`))
+	require.NoError(t, err)
+	assert.Empty(t, dn.Comments())
+}
+
+// run with -race to verify that the package configuration can change
+// while nodes are in use.
+func TestSetters_concurrent(t *testing.T) {
+	defer func() {
+		SetTraceProvider(nil)
+		SetTraceKey("")
+		SetTraceSeparator("")
+		SetValueTransformer(nil)
+		SetOnAdd(nil)
+		SetNodeIDFunc(nil)
+		SetCommentFormatter(nil)
+		SetRejectEmptyKeys(false)
+		SetBaggageByteLimit(0)
+		SetSpanAttrAllowlist()
+	}()
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			SetTraceProvider(callerFrame)
+			SetTraceKey(fmt.Sprint("trace_", i))
+			SetTraceSeparator(";")
+			SetValueTransformer(func(_ string, v any) any { return v })
+			SetOnAdd(func(string, any) {})
+			SetNodeIDFunc(func() string { return "id" })
+			SetCommentFormatter(func(c Comment) string { return c.Message })
+			SetRejectEmptyKeys(i%2 == 0)
+			SetBaggageByteLimit(i + 1)
+			SetSpanAttrAllowlist("k")
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			dn := (&Node{}).
+				AddValues(map[string]any{"k": i}).
+				AddLazy("lazy", func() any { return i }).
+				AddComment(0, "comment")
+
+			_ = dn.Map()
+			_ = dn.Comments().String()
+			_ = GetCaller(0)
+			_, _ = AddBaggage(context.Background(), "k", "v", nil)
+		}
+	}()
+
+	wg.Wait()
+}
+
+// ---------------------------------------------------------------------------
+// benchmarks
+// ---------------------------------------------------------------------------
+
+func benchNode() *Node {
+	dn := &Node{}
+
+	for i := 0; i < 20; i++ {
+		dn = dn.AddValues(map[string]any{fmt.Sprintf("key_%d", i): i})
+	}
+
+	return dn
+}
+
+func BenchmarkNode_MapAndSlice(b *testing.B) {
+	dn := benchNode()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = dn.MapAndSlice()
+	}
+}
+
+func BenchmarkNode_MapThenSlice(b *testing.B) {
+	dn := benchNode()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = dn.Map()
+		_ = dn.Slice()
+	}
+}
@@ -0,0 +1,40 @@
+package node
+
+// ---------------------------------------------------------------------------
+// operations
+// ---------------------------------------------------------------------------
+
+const (
+	// OpsKey is the key under which Map() records the operation stack.
+	OpsKey = "clues_ops"
+	// opsSeparator joins the ops in the operation stack value.
+	opsSeparator = ">"
+)
+
+// AddOp embeds the op in a new descendant node, pushing it onto the
+// operation stack.  Empty ops are ignored.
+func (dn *Node) AddOp(op string) *Node {
+	if len(op) == 0 {
+		return dn
+	}
+
+	spawn := dn.SpawnDescendant()
+	spawn.Op = op
+
+	return spawn
+}
+
+// Ops produces the operation stack along the node's ancestry path, from
+// the oldest ancestor to the current node.  Ops from other scopes are
+// skipped.
+func (dn *Node) Ops() []string {
+	var ops []string
+
+	dn.walkLineage(func(n *Node) {
+		if len(n.Op) > 0 {
+			ops = append(ops, n.Op)
+		}
+	})
+
+	return ops
+}
@@ -4,15 +4,21 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/alcionai/clues/internal/stringify"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/metric"
@@ -25,6 +31,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 // ------------------------------------------------------------
@@ -100,12 +107,28 @@ func (cli *OTELClient) Close(ctx context.Context) error {
 // config
 // ------------------------------------------------------------
 
+const (
+	// ProtocolGRPC exports telemetry using otlp over grpc.
+	ProtocolGRPC = "grpc"
+	// ProtocolHTTP exports telemetry using otlp over http.
+	ProtocolHTTP = "http"
+)
+
 type OTELConfig struct {
 	// specify the endpoint location to use for grpc communication.
 	// If empty, no telemetry exporter will be generated.
 	// ex: localhost:4317
 	// ex: 0.0.0.0:4317
 	GRPCEndpoint string
+
+	// specify the endpoint location to use for http communication.
+	// If empty, the otlp http exporters default to localhost:4318.
+	// ex: localhost:4318
+	HTTPEndpoint string
+
+	// Protocol selects the exporter transport.  Either ProtocolGRPC
+	// or ProtocolHTTP.  If empty, grpc is used.
+	Protocol string
 }
 
 // ------------------------------------------------------------
@@ -140,24 +163,36 @@ func NewOTELClient(
 		}
 	}
 
-	// -- grpc client
+	// -- exporters
 
-	// Note the use of insecure transport here. TLS is recommended in production.
-	creds := grpc.WithTransportCredentials(insecure.NewCredentials())
+	var exporters otelExporters
 
-	client.grpcConn, err = grpc.NewClient(config.GRPCEndpoint, creds)
-	if err != nil {
-		return nil, fmt.Errorf("creating new grpc connection: %w", err)
-	}
+	switch config.Protocol {
+	case "", ProtocolGRPC:
+		// Note the use of insecure transport here. TLS is recommended in production.
+		creds := grpc.WithTransportCredentials(insecure.NewCredentials())
 
-	// -- Tracing
+		client.grpcConn, err = grpc.NewClient(config.GRPCEndpoint, creds)
+		if err != nil {
+			return nil, fmt.Errorf("creating new grpc connection: %w", err)
+		}
+
+		exporters, err = newGRPCExporters(ctx, client.grpcConn)
+	case ProtocolHTTP:
+		exporters, err = newHTTPExporters(ctx, config.HTTPEndpoint)
+	default:
+		return nil, errors.Errorf("unsupported otel protocol %q", config.Protocol)
+	}
 
-	client.TracerProvider, err = newTracerProvider(ctx, client.grpcConn, server)
 	if err != nil {
 		closeClient()
-		return nil, errors.Wrap(err, "generating a tracer provider")
+		return nil, errors.Wrap(err, "generating otel exporters")
 	}
 
+	// -- Tracing
+
+	client.TracerProvider = newTracerProvider(exporters.trace, server)
+
 	// set propagation to include traceContext and baggage (the default is no-op).
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
 		propagation.TraceContext{},
@@ -169,22 +204,14 @@ func NewOTELClient(
 
 	// generate a logger provider
 	// LoggerProvider := global.GetLoggerProvider()
-	client.LoggerProvider, err = newLoggerProvider(ctx, client.grpcConn, server)
-	if err != nil {
-		closeClient()
-		return nil, errors.Wrap(err, "generating a logger provider")
-	}
+	client.LoggerProvider = newLoggerProvider(exporters.log, server)
 
 	global.SetLoggerProvider(client.LoggerProvider)
 	client.Logger = client.LoggerProvider.Logger(serviceName)
 
 	// -- Metrics
 
-	client.MeterProvider, err = newMeterProvider(ctx, client.grpcConn, server)
-	if err != nil {
-		closeClient()
-		return nil, errors.Wrap(err, "generating a meter provider")
-	}
+	client.MeterProvider = newMeterProvider(exporters.metric, server)
 
 	otel.SetMeterProvider(client.MeterProvider)
 	client.Meter = client.MeterProvider.Meter(serviceName)
@@ -193,26 +220,111 @@ func NewOTELClient(
 	return &client, nil
 }
 
-// newTracerProvider constructs a new tracer that manages batch exports
-// of tracing values.
-func newTracerProvider(
+// otelExporters holds the exporters for each telemetry signal.
+type otelExporters struct {
+	trace  sdkTrace.SpanExporter
+	metric sdkMetric.Exporter
+	log    sdkLog.Exporter
+}
+
+// newGRPCExporters constructs the otlp exporters which communicate
+// over the grpc connection.
+func newGRPCExporters(
 	ctx context.Context,
 	conn *grpc.ClientConn,
-	server *resource.Resource,
-) (*sdkTrace.TracerProvider, error) {
+) (otelExporters, error) {
+	var exps otelExporters
+
 	if ctx == nil {
-		return nil, errors.New("nil ctx")
+		return exps, errors.New("nil ctx")
 	}
 
 	var cancel context.CancelFunc
 	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
+	var err error
+
+	exps.trace, err = otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
+	if err != nil {
+		return exps, errors.Wrap(err, "constructing a tracer exporter")
+	}
+
+	exps.metric, err = otlpmetricgrpc.New(
+		ctx,
+		otlpmetricgrpc.WithGRPCConn(conn),
+		otlpmetricgrpc.WithCompressor("gzip"))
+	if err != nil {
+		return exps, errors.Wrap(err, "constructing a meter exporter")
+	}
+
+	exps.log, err = otlploggrpc.New(ctx, otlploggrpc.WithGRPCConn(conn))
+	if err != nil {
+		return exps, errors.Wrap(err, "constructing a logger exporter")
+	}
+
+	return exps, nil
+}
+
+// newHTTPExporters constructs the otlp exporters which communicate
+// over http.  If the endpoint is empty, the exporters use their
+// default endpoint.
+func newHTTPExporters(
+	ctx context.Context,
+	endpoint string,
+) (otelExporters, error) {
+	var exps otelExporters
+
+	if ctx == nil {
+		return exps, errors.New("nil ctx")
+	}
+
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Note the use of insecure transport here. TLS is recommended in production.
+	var (
+		traceOpts  = []otlptracehttp.Option{otlptracehttp.WithInsecure()}
+		metricOpts = []otlpmetrichttp.Option{
+			otlpmetrichttp.WithInsecure(),
+			otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression),
+		}
+		logOpts = []otlploghttp.Option{otlploghttp.WithInsecure()}
+	)
+
+	if len(endpoint) > 0 {
+		traceOpts = append(traceOpts, otlptracehttp.WithEndpoint(endpoint))
+		metricOpts = append(metricOpts, otlpmetrichttp.WithEndpoint(endpoint))
+		logOpts = append(logOpts, otlploghttp.WithEndpoint(endpoint))
+	}
+
+	var err error
+
+	exps.trace, err = otlptracehttp.New(ctx, traceOpts...)
+	if err != nil {
+		return exps, errors.Wrap(err, "constructing a tracer exporter")
+	}
+
+	exps.metric, err = otlpmetrichttp.New(ctx, metricOpts...)
+	if err != nil {
+		return exps, errors.Wrap(err, "constructing a meter exporter")
+	}
+
+	exps.log, err = otlploghttp.New(ctx, logOpts...)
 	if err != nil {
-		return nil, errors.Wrap(err, "constructing a tracer exporter")
+		return exps, errors.Wrap(err, "constructing a logger exporter")
 	}
 
+	return exps, nil
+}
+
+// newTracerProvider constructs a new tracer that manages batch exports
+// of tracing values.
+func newTracerProvider(
+	exporter sdkTrace.SpanExporter,
+	server *resource.Resource,
+) *sdkTrace.TracerProvider {
 	// Register the trace exporter with a TracerProvider, using a batch
 	// span processor to aggregate spans before export.
 	batchSpanProcessor := sdkTrace.NewBatchSpanProcessor(exporter)
@@ -224,7 +336,7 @@ func newTracerProvider(
 		// * blocking on full queue
 		// * max queue size
 		// FIXME: need to refine trace sampling.
-		sdkTrace.WithSampler(sdkTrace.AlwaysSample()),
+		sdkTrace.WithSampler(ctxSampler{fallback: sdkTrace.AlwaysSample()}),
 		sdkTrace.WithSpanProcessor(batchSpanProcessor),
 		sdkTrace.WithRawSpanLimits(sdkTrace.SpanLimits{
 			AttributeValueLengthLimit:   -1,
@@ -235,32 +347,45 @@ func newTracerProvider(
 			LinkCountLimit:              -1,
 		}))
 
-	return tracerProvider, nil
+	return tracerProvider
 }
 
-// newMeterProvider constructs a new meter that manages batch exports
-// of metrics.
-func newMeterProvider(
-	ctx context.Context,
-	conn *grpc.ClientConn,
-	server *resource.Resource,
-) (*sdkMetric.MeterProvider, error) {
-	if ctx == nil {
-		return nil, errors.New("nil ctx")
+// ctxSampler defers to the sampling decision recorded in the clues node
+// within the span's parent context.  If no decision was recorded, the
+// fallback sampler is used instead.
+type ctxSampler struct {
+	fallback sdkTrace.Sampler
+}
+
+func (cs ctxSampler) ShouldSample(
+	params sdkTrace.SamplingParameters,
+) sdkTrace.SamplingResult {
+	sampled, ok := FromCtx(params.ParentContext).IsSampled()
+	if !ok {
+		return cs.fallback.ShouldSample(params)
 	}
 
-	var cancel context.CancelFunc
-	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+	decision := sdkTrace.Drop
+	if sampled {
+		decision = sdkTrace.RecordAndSample
+	}
 
-	exporter, err := otlpmetricgrpc.New(
-		ctx,
-		otlpmetricgrpc.WithGRPCConn(conn),
-		otlpmetricgrpc.WithCompressor("gzip"))
-	if err != nil {
-		return nil, errors.Wrap(err, "constructing a meter exporter")
+	return sdkTrace.SamplingResult{
+		Decision:   decision,
+		Tracestate: trace.SpanContextFromContext(params.ParentContext).TraceState(),
 	}
+}
+
+func (cs ctxSampler) Description() string {
+	return "CluesCtxSampler{" + cs.fallback.Description() + "}"
+}
 
+// newMeterProvider constructs a new meter that manages batch exports
+// of metrics.
+func newMeterProvider(
+	exporter sdkMetric.Exporter,
+	server *resource.Resource,
+) *sdkMetric.MeterProvider {
 	periodicReader := sdkMetric.NewPeriodicReader(
 		exporter,
 		sdkMetric.WithInterval(1*time.Minute))
@@ -274,29 +399,15 @@ func newMeterProvider(
 		// * temporality
 		sdkMetric.WithReader(periodicReader))
 
-	return meterProvider, nil
+	return meterProvider
 }
 
 // newLoggerProvider constructs a new logger that manages batch exports
 // of logs.
 func newLoggerProvider(
-	ctx context.Context,
-	conn *grpc.ClientConn,
+	exporter sdkLog.Exporter,
 	server *resource.Resource,
-) (*sdkLog.LoggerProvider, error) {
-	if ctx == nil {
-		return nil, errors.New("nil ctx")
-	}
-
-	var cancel context.CancelFunc
-	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	exporter, err := otlploggrpc.New(ctx, otlploggrpc.WithGRPCConn(conn))
-	if err != nil {
-		return nil, errors.Wrap(err, "constructing a logger exporter")
-	}
-
+) *sdkLog.LoggerProvider {
 	loggerProvider := sdkLog.NewLoggerProvider(
 		sdkLog.WithResource(server),
 		// FIXME: need to investigate other options...
@@ -306,7 +417,7 @@ func newLoggerProvider(
 		// * value length limit
 		sdkLog.WithProcessor(sdkLog.NewBatchProcessor(exporter)))
 
-	return loggerProvider, nil
+	return loggerProvider
 }
 
 // ------------------------------------------------------------
@@ -365,12 +476,13 @@ type Annotationer interface {
 // to pass and receive traces using message delivery headers
 // and other metadata.
 type TraceMapCarrierBase interface {
-	map[string]string | http.Header
+	map[string]string | http.Header | metadata.MD
 }
 
 // AsTraceMapCarrier converts a traceMapCarrier interface to
 // its propagation package implementation for that structure.
-// ie: map becomes a MapCarrier, headers become HeaderCarriers.
+// ie: map becomes a MapCarrier, headers become HeaderCarriers,
+// and grpc metadata becomes a MetadataCarrier.
 func AsTraceMapCarrier[C TraceMapCarrierBase](
 	carrier C,
 ) propagation.TextMapCarrier {
@@ -386,9 +498,46 @@ func AsTraceMapCarrier[C TraceMapCarrierBase](
 		return propagation.HeaderCarrier(hh)
 	}
 
+	if md, ok := any(carrier).(metadata.MD); ok {
+		return MetadataCarrier(md)
+	}
+
 	return propagation.MapCarrier{}
 }
 
+// MetadataCarrier adapts grpc metadata to the propagation.TextMapCarrier
+// interface, so that traces can be passed and received using grpc
+// request metadata.
+type MetadataCarrier metadata.MD
+
+var _ propagation.TextMapCarrier = MetadataCarrier{}
+
+// Get returns the first value associated with the key.
+func (mc MetadataCarrier) Get(key string) string {
+	vs := metadata.MD(mc).Get(key)
+	if len(vs) == 0 {
+		return ""
+	}
+
+	return vs[0]
+}
+
+// Set replaces any values associated with the key.
+func (mc MetadataCarrier) Set(key, value string) {
+	metadata.MD(mc).Set(key, value)
+}
+
+// Keys lists the keys stored in the carrier.
+func (mc MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+
+	for k := range mc {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
 // injectTrace adds the current trace details to the provided
 // carrier.  If otel is not initialized, no-ops.
 //
@@ -425,20 +574,42 @@ func (dn *Node) ReceiveTrace(
 // continue to be used for that purpose until replaced with another
 // span, which will appear in a separate context (and thus a separate,
 // node).
+//
+// Nodes aren't held in any registry.  Each node is referenced only by its
+// descendants and by the ctxs and errors that embed it, so the nodes of a
+// closed span get reclaimed by the garbage collector along with the last
+// ctx that holds them.  Closing a span drops the node's references to the
+// span and its parent span.  However, every open and close adds a node to
+// the ctx's lineage, so loops which reassign the same ctx on each
+// iteration (ctx = AddSpan(ctx); ctx = CloseSpan(ctx)) grow the lineage,
+// and the cost of flattening it, without bound.  Loops should instead
+// start each span from the loop's parent ctx.
 func (dn *Node) AddSpan(
 	ctx context.Context,
 	name string,
+	opts ...trace.SpanStartOption,
 ) (context.Context, *Node) {
 	if dn == nil || dn.OTEL == nil {
 		return ctx, dn
 	}
 
-	ctx, span := dn.OTEL.Tracer.Start(ctx, name)
+	ctx, span := dn.OTEL.Tracer.Start(ctx, name, opts...)
+
+	return ctx, dn.WithSpan(span)
+}
 
+// WithSpan embeds the span in a new descendant node, making it the
+// current span.  The node becomes the span's parent, which is restored
+// by CloseSpanReturnParent.  Each span gets a single tracker of its
+// attribute keys, created here and shared by every descendant node, so
+// that the span attribute key limit applies across all of them.
+func (dn *Node) WithSpan(span trace.Span) *Node {
 	spawn := dn.SpawnDescendant()
 	spawn.Span = span
+	spawn.spanAttrKeys = &spanAttrKeys{}
+	spawn.spanParent = dn
 
-	return ctx, spawn
+	return spawn
 }
 
 // CloseSpan closes the otel span and removes it span from the data node.
@@ -452,13 +623,134 @@ func (dn *Node) CloseSpan(ctx context.Context) *Node {
 
 	spawn := dn.SpawnDescendant()
 	spawn.Span = nil
+	spawn.spanAttrKeys = nil
+	spawn.spanParent = nil
 
 	return spawn
 }
 
+// CloseSpanReturnParent closes the otel span, and returns a ctx and node
+// in which the parent span (the span that was current when this span was
+// added) is active again.  If there is no parent span, the ctx and node
+// are left without an active span.  If no span is present, no ops.
+func (dn *Node) CloseSpanReturnParent(ctx context.Context) (context.Context, *Node) {
+	if dn == nil || dn.Span == nil {
+		return ctx, dn
+	}
+
+	dn.Span.End()
+
+	spawn := dn.SpawnDescendant()
+	spawn.Span = nil
+	spawn.spanAttrKeys = nil
+	spawn.spanParent = nil
+
+	if parent := dn.spanParent; parent != nil {
+		spawn.Span = parent.Span
+		spawn.spanAttrKeys = parent.spanAttrKeys
+		spawn.spanParent = parent.spanParent
+	}
+
+	return trace.ContextWithSpan(ctx, spawn.Span), spawn
+}
+
+// spanAttrKeyLimit caps the number of distinct attribute keys that
+// AddSpanAttributes will set on a single span.  Zero means no limit.
+var spanAttrKeyLimit atomic.Int64
+
+// spanAttrTruncatedKey is the attribute set on a span once its attribute
+// keys have been capped by the spanAttrKeyLimit.
+const spanAttrTruncatedKey = "attrs_truncated"
+
+// SetSpanAttrKeyLimit caps the number of distinct attribute keys that get
+// added to any one span.  Values of n <= 0 remove the limit.
+func SetSpanAttrKeyLimit(n int) {
+	if n < 0 {
+		n = 0
+	}
+
+	spanAttrKeyLimit.Store(int64(n))
+}
+
+// spanAttrKeys counts the distinct attribute keys set on a span.
+type spanAttrKeys struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+// allow reports whether the key can be set on the span without exceeding
+// the limit.  Keys which were already set are always allowed.
+func (sak *spanAttrKeys) allow(k string, limit int) bool {
+	sak.mu.Lock()
+	defer sak.mu.Unlock()
+
+	if _, ok := sak.keys[k]; ok {
+		return true
+	}
+
+	if len(sak.keys) >= limit {
+		return false
+	}
+
+	if sak.keys == nil {
+		sak.keys = map[string]struct{}{}
+	}
+
+	sak.keys[k] = struct{}{}
+
+	return true
+}
+
+// spanAttrAllowlist holds the allowlisted span attribute keys.  A nil
+// pointer means no allowlist is set.  The map is never modified once
+// stored.
+var spanAttrAllowlist atomic.Pointer[map[string]struct{}]
+
+// SetSpanAttrAllowlist restricts the keys that AddSpanAttributes adds to
+// spans.  Calling it with no keys removes the allowlist.
+func SetSpanAttrAllowlist(keys ...string) {
+	if len(keys) == 0 {
+		spanAttrAllowlist.Store(nil)
+		return
+	}
+
+	allowlist := make(map[string]struct{}, len(keys))
+
+	for _, k := range keys {
+		allowlist[k] = struct{}{}
+	}
+
+	spanAttrAllowlist.Store(&allowlist)
+}
+
+// filterBySpanAttrAllowlist returns a copy of the values which only holds
+// the keys in the allowlist.  If no allowlist is set, the values are
+// returned as-is.
+func filterBySpanAttrAllowlist(values map[string]any) map[string]any {
+	allowlist := spanAttrAllowlist.Load()
+	if allowlist == nil {
+		return values
+	}
+
+	filtered := make(map[string]any, len(values))
+
+	for k, v := range values {
+		if _, ok := (*allowlist)[k]; ok {
+			filtered[k] = v
+		}
+	}
+
+	return filtered
+}
+
 // AddSpanAttributes adds the values to the current span.  If the span
 // is nil (such as if otel wasn't initialized or no span has been generated),
 // this call no-ops.
+//
+// If a span attribute allowlist is set, only the allowlisted keys are added.
+// If a span attribute key limit is set, keys beyond that limit are dropped
+// and the span is marked with attrs_truncated=true instead.  The limit
+// applies to spans added with AddSpan or WithSpan.
 func (dn *Node) AddSpanAttributes(
 	values map[string]any,
 ) {
@@ -466,9 +758,39 @@ func (dn *Node) AddSpanAttributes(
 		return
 	}
 
+	values = filterBySpanAttrAllowlist(values)
+
+	if len(values) == 0 {
+		return
+	}
+
+	limit := int(spanAttrKeyLimit.Load())
+
+	// the key tracker is created along with the span (see WithSpan).
+	// Spans which were attached some other way can't be limited.
+	if dn.spanAttrKeys == nil {
+		limit = 0
+	}
+
+	var (
+		attrs     = make([]attribute.KeyValue, 0, len(values))
+		truncated bool
+	)
+
 	for k, v := range values {
-		dn.Span.SetAttributes(attribute.String(k, stringify.Marshal(v, false)))
+		if limit > 0 && !dn.spanAttrKeys.allow(k, limit) {
+			truncated = true
+			continue
+		}
+
+		attrs = append(attrs, attribute.String(k, stringify.Marshal(v, false)))
 	}
+
+	if truncated {
+		attrs = append(attrs, attribute.Bool(spanAttrTruncatedKey, true))
+	}
+
+	dn.Span.SetAttributes(attrs...)
 }
 
 // logger gets the otel logger instance from the otel client.
@@ -490,3 +812,78 @@ func (dn *Node) OTELMeter() metric.Meter {
 
 	return dn.OTEL.Meter
 }
+
+// ------------------------------------------------------------
+// baggage
+// ------------------------------------------------------------
+
+// DefaultBaggageByteLimit is the maximum serialized size of the baggage
+// header, per the W3C baggage spec.
+const DefaultBaggageByteLimit = 8192
+
+// ErrBaggageLimit is returned when an addition would grow the serialized
+// baggage beyond the baggage byte limit.
+var ErrBaggageLimit = errors.New("baggage exceeds size limit")
+
+// baggageByteLimit caps the serialized size of the baggage in a ctx.
+// A zero value means the DefaultBaggageByteLimit is used.
+var baggageByteLimit atomic.Int64
+
+// SetBaggageByteLimit sets the maximum serialized size of the baggage.
+// Values of n <= 0 restore the default.
+func SetBaggageByteLimit(n int) {
+	if n <= 0 {
+		n = DefaultBaggageByteLimit
+	}
+
+	baggageByteLimit.Store(int64(n))
+}
+
+// loadBaggageByteLimit returns the current baggage byte limit.
+func loadBaggageByteLimit() int {
+	if n := baggageByteLimit.Load(); n > 0 {
+		return int(n)
+	}
+
+	return DefaultBaggageByteLimit
+}
+
+// AddBaggage sets the key:value member, along with any properties, in the
+// ctx's otel baggage.  Returns ErrBaggageLimit, and the unchanged ctx, if
+// the resulting baggage would exceed the baggage byte limit.
+func AddBaggage(
+	ctx context.Context,
+	k, v string,
+	props map[string]string,
+) (context.Context, error) {
+	properties := make([]baggage.Property, 0, len(props))
+
+	for pk, pv := range props {
+		prop, err := baggage.NewKeyValuePropertyRaw(pk, pv)
+		if err != nil {
+			return ctx, errors.Wrap(err, "creating baggage property")
+		}
+
+		properties = append(properties, prop)
+	}
+
+	member, err := baggage.NewMemberRaw(k, v, properties...)
+	if err != nil {
+		return ctx, errors.Wrap(err, "creating baggage member")
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, errors.Wrap(err, "setting baggage member")
+	}
+
+	if size, limit := len(bag.String()), loadBaggageByteLimit(); size > limit {
+		return ctx, errors.Wrapf(
+			ErrBaggageLimit,
+			"%d bytes exceeds limit of %d",
+			size,
+			limit)
+	}
+
+	return baggage.ContextWithBaggage(ctx, bag), nil
+}
@@ -0,0 +1,326 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewOTELClient_protocol(t *testing.T) {
+	table := []struct {
+		name       string
+		config     OTELConfig
+		expectGRPC bool
+		expectErr  require.ErrorAssertionFunc
+	}{
+		{
+			name:       "default",
+			config:     OTELConfig{},
+			expectGRPC: true,
+			expectErr:  require.NoError,
+		},
+		{
+			name:       "grpc",
+			config:     OTELConfig{Protocol: ProtocolGRPC, GRPCEndpoint: "localhost:4317"},
+			expectGRPC: true,
+			expectErr:  require.NoError,
+		},
+		{
+			name:      "http",
+			config:    OTELConfig{Protocol: ProtocolHTTP, HTTPEndpoint: "localhost:4318"},
+			expectErr: require.NoError,
+		},
+		{
+			name:      "http, default endpoint",
+			config:    OTELConfig{Protocol: ProtocolHTTP},
+			expectErr: require.NoError,
+		},
+		{
+			name:      "unsupported",
+			config:    OTELConfig{Protocol: "carrier-pigeon"},
+			expectErr: require.Error,
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			cli, err := NewOTELClient(context.Background(), t.Name(), test.config)
+			test.expectErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, test.expectGRPC, cli.grpcConn != nil)
+			assert.NotNil(t, cli.TracerProvider)
+			assert.NotNil(t, cli.MeterProvider)
+			assert.NotNil(t, cli.LoggerProvider)
+		})
+	}
+}
+
+func TestNewExporters(t *testing.T) {
+	ctx := context.Background()
+
+	exps, err := newHTTPExporters(ctx, "localhost:4318")
+	require.NoError(t, err)
+	assert.NotNil(t, exps.trace)
+	assert.IsType(t, &otlpmetrichttp.Exporter{}, exps.metric)
+	assert.IsType(t, &otlploghttp.Exporter{}, exps.log)
+
+	cli, err := NewOTELClient(ctx, t.Name(), OTELConfig{})
+	require.NoError(t, err)
+
+	exps, err = newGRPCExporters(ctx, cli.grpcConn)
+	require.NoError(t, err)
+	assert.NotNil(t, exps.trace)
+	assert.IsType(t, &otlpmetricgrpc.Exporter{}, exps.metric)
+	assert.IsType(t, &otlploggrpc.Exporter{}, exps.log)
+}
+
+func TestNode_AddSpanAttributes(t *testing.T) {
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder))
+		_, span  = provider.Tracer("test").Start(context.Background(), "span")
+		dn       = &Node{Span: span}
+	)
+
+	dn.AddSpanAttributes(map[string]any{
+		"string": "s",
+		"int":    1,
+		"nil":    nil,
+	})
+	dn.AddSpanAttributes(map[string]any{})
+	span.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+
+	assert.ElementsMatch(
+		t,
+		[]attribute.KeyValue{
+			attribute.String("string", "s"),
+			attribute.String("int", "1"),
+			attribute.String("nil", ""),
+		},
+		ended[0].Attributes())
+}
+
+func TestNode_AddSpanAttributes_keyLimit(t *testing.T) {
+	SetSpanAttrKeyLimit(3)
+	defer SetSpanAttrKeyLimit(0)
+
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder))
+		_, span  = provider.Tracer("test").Start(context.Background(), "span")
+		root     = (&Node{}).WithSpan(span)
+		dn       = root.AddValues(map[string]any{"a": 1, "b": 2})
+	)
+
+	// descendants, including siblings, share the span's key count.
+	root.AddValues(map[string]any{"c": 3})
+	dn = dn.AddValues(map[string]any{"d": 4, "e": 5})
+	dn.AddValues(map[string]any{"a": "updated", "f": 6})
+
+	// nodes given a span without a tracker are never modified.
+	_, other := provider.Tracer("test").Start(context.Background(), "other")
+	untracked := &Node{Span: other}
+	untracked.AddSpanAttributes(map[string]any{"g": 7})
+	assert.Nil(t, untracked.spanAttrKeys)
+	span.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+
+	var (
+		attrs     = ended[0].Attributes()
+		keys      = map[attribute.Key]attribute.Value{}
+		truncated bool
+	)
+
+	for _, attr := range attrs {
+		if attr.Key == spanAttrTruncatedKey {
+			truncated = attr.Value.AsBool()
+			continue
+		}
+
+		keys[attr.Key] = attr.Value
+	}
+
+	assert.True(t, truncated, "span should be marked as truncated")
+	assert.Len(t, keys, 3)
+	assert.Equal(t, "updated", keys["a"].AsString())
+	assert.Contains(t, keys, attribute.Key("b"))
+	assert.NotContains(t, keys, attribute.Key("f"))
+}
+
+func TestNode_AddSpanAttributes_allowlist(t *testing.T) {
+	SetSpanAttrAllowlist("keep", "also_keep")
+	defer SetSpanAttrAllowlist()
+
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder))
+		_, span  = provider.Tracer("test").Start(context.Background(), "span")
+		dn       = &Node{Span: span}
+	)
+
+	dn = dn.AddValues(map[string]any{"keep": 1, "drop": 2})
+	dn = dn.AddValues(map[string]any{"also_keep": 3})
+	dn = dn.AddValues(map[string]any{"only_drop": 4})
+	span.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+
+	assert.ElementsMatch(
+		t,
+		[]attribute.KeyValue{
+			attribute.String("keep", "1"),
+			attribute.String("also_keep", "3"),
+		},
+		ended[0].Attributes())
+
+	// the node retains every value.
+	assert.Equal(
+		t,
+		map[string]any{"keep": 1, "drop": 2, "also_keep": 3, "only_drop": 4},
+		dn.Map())
+}
+
+func TestNode_CloseSpanReturnParent(t *testing.T) {
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder))
+		ctx      = context.Background()
+		root     = &Node{OTEL: &OTELClient{Tracer: provider.Tracer("test")}}
+	)
+
+	ctx, parent := root.AddSpan(ctx, "parent")
+	parent = parent.AddValues(map[string]any{"p": 1})
+
+	ctx, child := parent.AddSpan(ctx, "child")
+	child = child.AddValues(map[string]any{"c": 1})
+
+	ctx, returned := child.CloseSpanReturnParent(ctx)
+	require.Equal(t, parent.Span, returned.Span)
+	require.Equal(t, parent.Span, trace.SpanFromContext(ctx))
+
+	returned.AddValues(map[string]any{"after": 1})
+
+	_, sibling := returned.AddSpan(ctx, "sibling")
+	sibling.Span.End()
+
+	_, ended := returned.CloseSpanReturnParent(ctx)
+	assert.Nil(t, ended.Span, "no span after closing the root span")
+
+	spans := map[string]sdkTrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+
+	require.Len(t, spans, 3)
+
+	parentSC := spans["parent"].SpanContext()
+	assert.Equal(t, parentSC.SpanID(), spans["child"].Parent().SpanID())
+	assert.Equal(t, parentSC.SpanID(), spans["sibling"].Parent().SpanID())
+
+	assert.ElementsMatch(
+		t,
+		[]attribute.KeyValue{
+			attribute.String("p", "1"),
+			attribute.String("after", "1"),
+		},
+		spans["parent"].Attributes())
+	assert.ElementsMatch(
+		t,
+		[]attribute.KeyValue{attribute.String("c", "1")},
+		spans["child"].Attributes())
+}
+
+// lineageLen counts the nodes in the node's ancestry, including itself.
+func lineageLen(dn *Node) int {
+	n := 0
+
+	for ; dn != nil; dn = dn.Parent {
+		n++
+	}
+
+	return n
+}
+
+func TestNode_spanCycles(t *testing.T) {
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder))
+		ctx      = context.Background()
+		root     = &Node{OTEL: &OTELClient{Tracer: provider.Tracer("test")}}
+		base     = root.AddValues(map[string]any{"k": "v"})
+		baseLen  = lineageLen(base)
+	)
+
+	for i := 0; i < 1000; i++ {
+		sctx, spanned := base.AddSpan(ctx, "cycle")
+		spanned = spanned.AddValues(map[string]any{"i": i})
+
+		_, closed := spanned.CloseSpanReturnParent(sctx)
+
+		require.Nil(t, closed.Span, "closed nodes drop the span")
+		require.Nil(t, closed.spanParent, "closed nodes drop the parent span node")
+		require.Nil(t, closed.spanAttrKeys, "closed nodes drop the span attr keys")
+		require.Equal(t, baseLen+3, lineageLen(closed), "cycles don't accumulate nodes")
+	}
+
+	assert.Equal(t, baseLen, lineageLen(base), "the parent lineage is untouched")
+	assert.Equal(t, map[string]any{"k": "v"}, base.Map())
+	assert.Len(t, recorder.Ended(), 1000)
+}
+
+func BenchmarkNode_spanCycle(b *testing.B) {
+	var (
+		provider = sdkTrace.NewTracerProvider()
+		ctx      = context.Background()
+		root     = &Node{OTEL: &OTELClient{Tracer: provider.Tracer("bench")}}
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sctx, spanned := root.AddSpan(ctx, "cycle")
+		spanned.CloseSpanReturnParent(sctx)
+	}
+}
+
+func BenchmarkNode_AddSpanAttributes(b *testing.B) {
+	var (
+		provider = sdkTrace.NewTracerProvider()
+		_, span  = provider.Tracer("bench").Start(context.Background(), "span")
+		dn       = &Node{Span: span}
+		values   = map[string]any{}
+	)
+
+	defer span.End()
+
+	for i := 0; i < 20; i++ {
+		values[fmt.Sprintf("key_%d", i)] = i
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		dn.AddSpanAttributes(values)
+	}
+}
@@ -5,8 +5,66 @@ import (
 	"path"
 	"runtime"
 	"strings"
+	"sync/atomic"
 )
 
+// Frame describes a single caller in the call stack.
+type Frame struct {
+	// Func is the fully qualified func name.
+	// ex: github.com/alcionai/clues/cluerr.New
+	Func string
+	// File is the absolute path to the file containing the func.
+	File string
+	// Line is the line number within the file.
+	Line int
+}
+
+// TraceProvider produces the Frame for the caller at the given depth.
+// Depth is the skip-caller count, where 0 is the func that called the
+// provider, similar to runtime.Caller.
+type TraceProvider func(depth int) Frame
+
+// traceProvider holds the func used to capture caller info.  A nil
+// pointer means callerFrame is used.
+var traceProvider atomic.Pointer[TraceProvider]
+
+// SetTraceProvider replaces the func used to capture caller info.  If
+// the provider is nil, the default runtime.Caller implementation is
+// restored.
+func SetTraceProvider(provider TraceProvider) {
+	if provider == nil {
+		provider = callerFrame
+	}
+
+	traceProvider.Store(&provider)
+}
+
+// loadTraceProvider returns the current trace provider.
+func loadTraceProvider() TraceProvider {
+	if provider := traceProvider.Load(); provider != nil {
+		return *provider
+	}
+
+	return callerFrame
+}
+
+// callerFrame is the default trace provider.  It uses runtime.Caller
+// to look up the caller info.
+func callerFrame(depth int) Frame {
+	pc, file, line, ok := runtime.Caller(depth + 1)
+	frame := Frame{File: file, Line: line}
+
+	if !ok {
+		return frame
+	}
+
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		frame.Func = fn.Name()
+	}
+
+	return frame
+}
+
 // GetDirAndFile retrieves the file and line number of the caller.
 // Depth is the skip-caller count.  Clues funcs that call this one should
 // provide either `1` (if they do not already have a depth value), or `depth+1`
@@ -19,10 +77,10 @@ import (
 func GetDirAndFile(
 	depth int,
 ) (dir, fileAndLine, parentAndFileAndLine string) {
-	_, file, line, _ := runtime.Caller(depth + 1)
-	dir, file = path.Split(file)
+	frame := loadTraceProvider()(depth + 1)
+	dir, file := path.Split(frame.File)
 
-	fileLine := fmt.Sprintf("%s:%d", file, line)
+	fileLine := fmt.Sprintf("%s:%d", file, frame.Line)
 	parentFileLine := fileLine
 
 	parent := path.Base(dir)
@@ -37,13 +95,11 @@ func GetDirAndFile(
 // count.  Clues funcs that call this one should provide either `1` (if they
 // do not already have a depth value), or `depth+1` otherwise.`
 func GetCaller(depth int) string {
-	pc, _, _, ok := runtime.Caller(depth + 1)
-	if !ok {
+	funcPath := loadTraceProvider()(depth + 1).Func
+	if len(funcPath) == 0 {
 		return ""
 	}
 
-	funcPath := runtime.FuncForPC(pc).Name()
-
 	// the funcpath base looks something like this:
 	// prefix.funcName[...].foo.bar
 	// with the [...] only appearing for funcs with generics.
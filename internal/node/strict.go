@@ -0,0 +1,84 @@
+package node
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync/atomic"
+)
+
+// ---------------------------------------------------------------------------
+// strict mode
+// ---------------------------------------------------------------------------
+
+var (
+	strictMode atomic.Bool
+	strictWarn atomic.Pointer[func(string)]
+)
+
+// strictTracker records whether a node embedded in a ctx has been read
+// back out of that ctx.
+type strictTracker struct {
+	// the file and line where the node was embedded.
+	origin string
+	read   atomic.Bool
+}
+
+// SetStrictMode toggles strict mode.  While enabled, every node embedded
+// in a ctx is tracked.  If the node gets garbage collected without ever
+// being read back out of a ctx, the warn func is called with a message
+// describing where the node was embedded.  This helps catch bugs where
+// the ctx returned by an adder is dropped.
+//
+// If warn is nil, warnings are printed to stderr.
+func SetStrictMode(enabled bool, warn func(string)) {
+	if warn == nil {
+		warn = func(msg string) {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+	}
+
+	strictWarn.Store(&warn)
+	strictMode.Store(enabled)
+}
+
+// trackStrict begins tracking the node, if strict mode is enabled and
+// the node isn't already tracked.  Depth is the skip-caller count for
+// the func that embedded the node.  Only the first embedding of a node
+// gets tracked, even when embedded concurrently.
+func trackStrict(dn *Node, depth int) {
+	if dn == nil || dn.strict.Load() != nil || !strictMode.Load() {
+		return
+	}
+
+	_, _, origin := GetDirAndFile(depth + 1)
+
+	if !dn.strict.CompareAndSwap(nil, &strictTracker{origin: origin}) {
+		return
+	}
+
+	runtime.SetFinalizer(dn, func(n *Node) {
+		tracker := n.strict.Load()
+		if tracker.read.Load() {
+			return
+		}
+
+		if warn := strictWarn.Load(); warn != nil {
+			(*warn)(fmt.Sprintf(
+				"clues strict mode: the ctx produced at %s was never used; "+
+					"did you drop the ctx returned by a clues func?",
+				tracker.origin))
+		}
+	})
+}
+
+// markRead records that the node was read from a ctx.
+func (dn *Node) markRead() {
+	if dn == nil {
+		return
+	}
+
+	if tracker := dn.strict.Load(); tracker != nil {
+		tracker.read.Store(true)
+	}
+}
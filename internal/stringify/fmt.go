@@ -3,6 +3,8 @@ package stringify
 import (
 	"fmt"
 	"reflect"
+	"sync/atomic"
+	"time"
 )
 
 // ---------------------------------------------------------------------------
@@ -24,6 +26,70 @@ type Concealer interface {
 	PlainString() string
 }
 
+// OddArgsPolicy determines how a dangling key, one without a paired value,
+// gets handled in a variadic of key-value pairs.
+type OddArgsPolicy int
+
+const (
+	// OddArgsNil pairs the dangling key with a nil value.
+	OddArgsNil OddArgsPolicy = iota
+	// OddArgsDrop discards the dangling key.
+	OddArgsDrop
+	// OddArgsMissing pairs the dangling key with the MissingValue.
+	OddArgsMissing
+)
+
+// MissingValue is paired with dangling keys under the OddArgsMissing policy.
+const MissingValue = "<missing>"
+
+// oddArgsPolicy holds the OddArgsPolicy.  The zero value is OddArgsNil.
+var oddArgsPolicy atomic.Int64
+
+// SetOddArgsPolicy sets the policy used to handle dangling keys.
+func SetOddArgsPolicy(policy OddArgsPolicy) {
+	oddArgsPolicy.Store(int64(policy))
+}
+
+// DanglingValue produces the value paired with a dangling key according
+// to the current odd args policy.  Returns false if the key should be
+// dropped.
+func DanglingValue() (any, bool) {
+	switch OddArgsPolicy(oddArgsPolicy.Load()) {
+	case OddArgsDrop:
+		return nil, false
+	case OddArgsMissing:
+		return MissingValue, true
+	default:
+		return nil, true
+	}
+}
+
+// DefaultTimeFormat is the layout used to render time.Time values.
+const DefaultTimeFormat = time.RFC3339Nano
+
+// timeFormat holds the layout set by SetTimeFormat.  A nil pointer means
+// the DefaultTimeFormat is used.
+var timeFormat atomic.Pointer[string]
+
+// SetTimeFormat sets the layout used to render time.Time values.  An
+// empty layout restores the DefaultTimeFormat.
+func SetTimeFormat(layout string) {
+	if len(layout) == 0 {
+		layout = DefaultTimeFormat
+	}
+
+	timeFormat.Store(&layout)
+}
+
+// TimeFormat returns the layout used to render time.Time values.
+func TimeFormat() string {
+	if layout := timeFormat.Load(); layout != nil {
+		return *layout
+	}
+
+	return DefaultTimeFormat
+}
+
 // ---------------------------------------------------------------------------
 // funcs
 // ---------------------------------------------------------------------------
@@ -48,8 +114,9 @@ func Fmt(vals ...any) []string {
 // 1. nil -> ""
 // 2. conceal all concealer interfaces
 // 3. flat string values
-// 4. string all stringer interfaces
-// 5. fmt.sprintf the rest
+// 4. time values, formatted with the time format
+// 5. string all stringer interfaces
+// 6. fmt.sprintf the rest
 func Marshal(a any, shouldConceal bool) string {
 	if a == nil {
 		return ""
@@ -69,6 +136,13 @@ func Marshal(a any, shouldConceal bool) string {
 		return as
 	}
 
+	switch as := a.(type) {
+	case time.Time:
+		return as.Format(TimeFormat())
+	case *time.Time:
+		return as.Format(TimeFormat())
+	}
+
 	if as, ok := a.(fmt.Stringer); ok {
 		return as.String()
 	}
@@ -77,20 +151,24 @@ func Marshal(a any, shouldConceal bool) string {
 }
 
 // Normalize ensures that the variadic of key-value pairs is even in length,
-// and then transforms that slice of values into a map[string]any, where all
+// pairing any dangling key according to the odd args policy, and then
+// transforms that slice of values into a map[string]any, where all
 // keys are transformed to string using the marshal() func.
 func Normalize(kvs ...any) map[string]any {
-	norm := map[string]any{}
+	norm := make(map[string]any, (len(kvs)+1)/2)
 
 	for i := 0; i < len(kvs); i += 2 {
 		key := Marshal(kvs[i], true)
 
-		var value any
-		if i+1 < len(kvs) {
-			value = Marshal(kvs[i+1], true)
+		if i+1 >= len(kvs) {
+			if value, ok := DanglingValue(); ok {
+				norm[key] = value
+			}
+
+			break
 		}
 
-		norm[key] = value
+		norm[key] = Marshal(kvs[i+1], true)
 	}
 
 	return norm
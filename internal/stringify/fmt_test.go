@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -171,3 +172,34 @@ func TestNormalize(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshal_time(t *testing.T) {
+	when := time.Date(2024, 2, 3, 4, 5, 6, 7, time.UTC)
+
+	table := []struct {
+		name   string
+		layout string
+		expect string
+	}{
+		{
+			name:   "default",
+			expect: "2024-02-03T04:05:06.000000007Z",
+		},
+		{
+			name:   "custom",
+			layout: time.DateTime,
+			expect: "2024-02-03 04:05:06",
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			SetTimeFormat(test.layout)
+			defer SetTimeFormat("")
+
+			assert.Equal(t, test.expect, Marshal(when, false))
+			assert.Equal(t, test.expect, Marshal(&when, false))
+			assert.Equal(t, map[string]any{"when": test.expect}, Normalize("when", when))
+		})
+	}
+}
@@ -10,6 +10,19 @@ var ErrMissingOtelGRPCEndpoint = errors.New("missing otel grpc endpoint")
 
 const (
 	DefaultOTELGRPCEndpoint = "localhost:4317"
+	DefaultOTELHTTPEndpoint = "localhost:4318"
+)
+
+// OTELProtocol selects the transport used to export telemetry.
+type OTELProtocol string
+
+const (
+	// OTELProtocolGRPC exports telemetry using otlp over grpc.  This
+	// is the default.
+	OTELProtocolGRPC OTELProtocol = node.ProtocolGRPC
+	// OTELProtocolHTTP exports telemetry using otlp over http, for
+	// environments which only allow http traffic.
+	OTELProtocolHTTP OTELProtocol = node.ProtocolHTTP
 )
 
 type OTELConfig struct {
@@ -19,11 +32,24 @@ type OTELConfig struct {
 	// ex: 0.0.0.0:4317
 	// ex: opentelemetry-collector.monitoring.svc.cluster.local:4317
 	GRPCEndpoint string
+
+	// specify the endpoint location to use for http communication.
+	// Only used when the Protocol is OTELProtocolHTTP.
+	// If empty, defaults to localhost:4318.
+	// ex: localhost:4318
+	// ex: opentelemetry-collector.monitoring.svc.cluster.local:4318
+	HTTPEndpoint string
+
+	// Protocol selects the transport used to export telemetry.
+	// If empty, defaults to OTELProtocolGRPC.
+	Protocol OTELProtocol
 }
 
 // clues.OTELConfig is a passthrough to the internal otel config.
 func (oc OTELConfig) toInternalConfig() node.OTELConfig {
 	return node.OTELConfig{
 		GRPCEndpoint: oc.GRPCEndpoint,
+		HTTPEndpoint: oc.HTTPEndpoint,
+		Protocol:     string(oc.Protocol),
 	}
 }